@@ -1,13 +1,19 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/pivotal-cloudops/omen/internal/applychanges"
+	"github.com/pivotal-cloudops/omen/internal/configdiff"
+	"github.com/pivotal-cloudops/omen/internal/installations"
 	"github.com/pivotal-cloudops/omen/internal/manifest"
+	"github.com/pivotal-cloudops/omen/internal/predeploy"
 	"github.com/pivotal-cloudops/omen/internal/tile"
+	"github.com/pivotal-cloudops/omen/internal/userio"
 	"github.com/spf13/cobra"
 )
 
@@ -15,12 +21,27 @@ var nonInteractive bool
 var products string
 var dryRun bool
 var quiet bool
+var wait bool
+var planFile string
+var planOutput string
+var ignoreWarnings bool
+var skipErrands []string
+var runErrands []string
+var outputFormat string
+var diffFormat string
+var showSecrets bool
+var includeConfigDiff bool
 
 var applyChangesCmd = &cobra.Command{
 	Use:   "apply-changes",
 	Short: "apply any staged changes",
 	Long:  "Produces a diff of staged versus deployed changes and then applies those staged changes",
-	Run:   applyChangesFunc,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		rp.JSON = outputFormat == applychanges.OutputJSON
+		configureLogging()
+		applyProfile()
+	},
+	Run: applyChangesFunc,
 }
 
 func init() {
@@ -35,15 +56,61 @@ func init() {
 
 	applyChangesCmd.Flags().BoolVarP(&quiet, "quiet", "q", false,
 		"Set this flag to suppress the diff output for apply changes")
+
+	applyChangesCmd.Flags().BoolVarP(&wait, "wait", "w", false,
+		"Set this flag to wait for the installation to finish, streaming its logs and failing if it fails")
+
+	applyChangesCmd.Flags().StringVar(&planOutput, "out", "",
+		"Optional path to write a plan of the reviewed changes to (requires --dry-run)")
+
+	applyChangesCmd.Flags().StringVar(&planFile, "plan", "",
+		"Optional path to a plan written with --out; changes are only applied if nothing has been staged since")
+
+	applyChangesCmd.Flags().BoolVar(&ignoreWarnings, "ignore-warnings", false,
+		"Set this flag to apply changes even when Ops Manager verifiers report warnings")
+
+	applyChangesCmd.Flags().StringSliceVar(&skipErrands, "skip-errands", []string{},
+		`(Optional) A comma-delimited list of post-deploy errands to skip for this run only (e.g. "p-redis:smoke-tests")`)
+
+	applyChangesCmd.Flags().StringSliceVar(&runErrands, "run-errands", []string{},
+		`(Optional) A comma-delimited list of post-deploy errands to run for this run only (e.g. "cf:push-apps-manager")`)
+
+	applyChangesCmd.Flags().StringVar(&outputFormat, "output", applychanges.OutputText,
+		`(Optional) Set to "json" to print a single machine-readable result document instead of the human output`)
+
+	applyChangesCmd.Flags().StringVar(&diffFormat, "diff-format", applychanges.DiffFormatText,
+		`(Optional) Set to "json" to print the diff as a list of typed change records`)
+
+	applyChangesCmd.Flags().BoolVar(&showSecrets, "show-secrets", false,
+		"Set this flag to show passwords, keys and certificates in the diff instead of redacting them")
+
+	applyChangesCmd.Flags().BoolVar(&includeConfigDiff, "include-config-diff", false,
+		"Set this flag to also show the changes to tile properties, resources, networks and errands")
+
+	addDiffRenderFlags(applyChangesCmd)
+
+	addPreflightFlags(applyChangesCmd)
 }
 
 var applyChangesFunc = func(cmd *cobra.Command, args []string) {
-	c := setupOpsmanClient()
-	tl := tile.NewTilesLoader(c)
-	ml := manifest.NewManifestsLoader(c, tl)
+	validateApplyChangesFlags()
+
+	ctx := commandContext()
+	c, err := setupOpsmanClient(ctx)
+	if err != nil {
+		rp.Fail(err)
+	}
+	if !dryRun {
+		checkForRunningInstallation(ctx, c, progressOutput())
+	}
+
+	tl := tile.NewTilesLoader(c, loaderParallelism())
+	ml := manifest.NewManifestsLoader(c, tl, loaderParallelism())
 
 	var slugs []string
-	if len(products) == 0 {
+	if planFile != "" {
+		printMessage("Applying changes from plan", planFile)
+	} else if len(products) == 0 {
 		printMessage("Applying changes to all products")
 	} else {
 		printMessage("Applying changes to these products:", products)
@@ -53,25 +120,105 @@ var applyChangesFunc = func(cmd *cobra.Command, args []string) {
 		}
 	}
 
+	errandOverrides, err := parseErrandOverrides()
+	if err != nil {
+		rp.Fail(err)
+	}
+
+	renderOptions, err := diffRenderOptions()
+	if err != nil {
+		rp.Fail(err)
+	}
+
 	options := applychanges.ApplyChangesOptions{
-		TileSlugs:      slugs,
-		NonInteractive: nonInteractive,
-		DryRun:         dryRun,
-		Quiet:          quiet,
+		TileSlugs:         slugs,
+		NonInteractive:    nonInteractive,
+		DryRun:            dryRun,
+		Quiet:             quiet,
+		Wait:              wait,
+		PlanFile:          planFile,
+		PlanOutput:        planOutput,
+		IgnoreWarnings:    ignoreWarnings,
+		ErrandOverrides:   errandOverrides,
+		OutputFormat:      outputFormat,
+		DiffFormat:        diffFormat,
+		ShowSecrets:       showSecrets,
+		DiffStyle:         renderOptions.Style,
+		DiffContext:       renderOptions.Context,
+		Color:             renderOptions.Color,
+		IgnoreRules:       renderOptions.Ignore,
+		IncludeConfigDiff: includeConfigDiff,
+	}
+
+	logWriter := os.Stdout
+	if quiet || outputFormat == applychanges.OutputJSON {
+		logWriter = os.Stderr
+	}
+	iw := installations.NewWatcher(c, logWriter, 10*time.Second)
+
+	pc := predeploy.NewChecker(c)
+
+	cd := configdiff.NewDiffer(tl, renderOptions, showSecrets)
+
+	op := applychanges.NewApplyChangesOp(ml, tl, c, rp, iw, pc, cd, options)
+
+	err = op.Execute(ctx)
+
+	if err != nil && outputFormat == applychanges.OutputJSON {
+		// apply-changes has printed its failed result document already
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(userio.ExitCode(err))
+	}
+	rp.Fail(err)
+}
+
+func validateApplyChangesFlags() {
+	if planOutput != "" && !dryRun {
+		rp.Fail(errors.New("--out can only be used together with --dry-run"))
 	}
-	op := applychanges.NewApplyChangesOp(ml, tl, c, rp, options)
 
-	err := op.Execute()
+	if outputFormat != applychanges.OutputText && outputFormat != applychanges.OutputJSON {
+		rp.Fail(fmt.Errorf("invalid value %q for --output, expected text or json", outputFormat))
+	}
 
+	if diffFormat != applychanges.DiffFormatText && diffFormat != applychanges.DiffFormatJSON {
+		rp.Fail(fmt.Errorf("invalid value %q for --diff-format, expected text or json", diffFormat))
+	}
+
+	if outputFormat == applychanges.OutputJSON && !dryRun && !nonInteractive {
+		rp.Fail(errors.New("--output json requires --non-interactive or --dry-run"))
+	}
+
+	if planFile != "" && len(products) > 0 {
+		rp.Fail(errors.New("--plan cannot be combined with --products; the plan already selects the products"))
+	}
+}
+
+func parseErrandOverrides() ([]applychanges.ErrandOverride, error) {
+	skips, err := applychanges.ParseErrandOverrides(skipErrands, false)
 	if err != nil {
-		rp.Fail(err)
+		return nil, err
 	}
+
+	runs, err := applychanges.ParseErrandOverrides(runErrands, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(skips, runs...), nil
 }
 
-func printMessage(message ... string) {
-	if quiet {
-		fmt.Fprintln(os.Stderr, message)
-	} else {
-		fmt.Println(message)
+func progressOutput() progressPrinter {
+	if quiet || outputFormat == applychanges.OutputJSON {
+		return stderrPrinter{}
+	}
+	return rp
+}
+
+func printMessage(message ...string) {
+	out := os.Stdout
+	if quiet || outputFormat == applychanges.OutputJSON {
+		out = os.Stderr
 	}
+	userio.Logger{Out: out, Level: logger.Level}.Infof("%s", message)
 }
@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pivotal-cloudops/omen/internal/compare"
+	"github.com/pivotal-cloudops/omen/internal/diff"
+	"github.com/pivotal-cloudops/omen/internal/manifest"
+	"github.com/pivotal-cloudops/omen/internal/redact"
+	"github.com/pivotal-cloudops/omen/internal/tile"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+type foundationFlags struct {
+	target       string
+	user         string
+	password     string
+	clientID     string
+	clientSecret string
+}
+
+var foundationA, foundationB foundationFlags
+var compareShowSecrets bool
+
+var compareCmd = &cobra.Command{
+	Use:   "compare",
+	Short: "compare the staged configuration of two foundations",
+	Long: "Compares the staged tile properties, resources, errands, networks and manifests of two Ops Managers, " +
+		"matching products by slug",
+	Run: func(cmd *cobra.Command, args []string) {
+		options, err := diffRenderOptions()
+		if err != nil {
+			rp.Fail(err)
+		}
+
+		if foundationA.target == "" || foundationB.target == "" {
+			rp.Fail(errors.New("--target-a and --target-b are required"))
+		}
+
+		ctx := commandContext()
+		a := loadFoundation(ctx, foundationA)
+		b := loadFoundation(ctx, foundationB)
+
+		var before, after interface{} = a.Document(), b.Document()
+		if !compareShowSecrets {
+			before, err = redact.Redact(before)
+			if err != nil {
+				rp.Fail(err)
+			}
+
+			after, err = redact.Redact(after)
+			if err != nil {
+				rp.Fail(err)
+			}
+		}
+
+		d, err := diff.Render(before, after, options)
+		if err != nil {
+			rp.Fail(err)
+		}
+
+		rp.PrintReport(fmt.Sprintf("--- %s\n+++ %s\n", a.Name, b.Name))
+		if d == "" {
+			rp.PrintReport("No differences")
+			return
+		}
+		rp.PrintReport(d)
+	},
+}
+
+func init() {
+	addFoundationFlags(&foundationA, "a")
+	addFoundationFlags(&foundationB, "b")
+
+	compareCmd.Flags().BoolVar(&compareShowSecrets, "show-secrets", false,
+		"Set this flag to show passwords, keys and certificates instead of redacting them")
+
+	addDiffRenderFlags(compareCmd)
+}
+
+func addFoundationFlags(f *foundationFlags, suffix string) {
+	compareCmd.Flags().StringVar(&f.target, "target-"+suffix, "",
+		fmt.Sprintf("URL of Ops Manager %s", suffix))
+	compareCmd.Flags().StringVar(&f.user, "username-"+suffix, "",
+		fmt.Sprintf("Ops Manager %s user name (defaults to the global credentials)", suffix))
+	compareCmd.Flags().StringVar(&f.password, "password-"+suffix, "",
+		fmt.Sprintf("Ops Manager %s password (defaults to the global credentials)", suffix))
+	compareCmd.Flags().StringVar(&f.clientID, "client-id-"+suffix, "",
+		fmt.Sprintf("Ops Manager %s client ID (defaults to the global credentials)", suffix))
+	compareCmd.Flags().StringVar(&f.clientSecret, "client-secret-"+suffix, "",
+		fmt.Sprintf("Ops Manager %s client secret (defaults to the global credentials)", suffix))
+}
+
+// loadFoundation connects with the foundation's own credentials, or with the
+// global ones when none are given for it.
+func loadFoundation(ctx context.Context, f foundationFlags) compare.Foundation {
+	if f.user == "" && f.password == "" && f.clientID == "" && f.clientSecret == "" {
+		f.user = viper.GetString(keyUser)
+		f.password = viper.GetString(keyPassword)
+		f.clientID = viper.GetString(keyClientId)
+		f.clientSecret = viper.GetString(keyClientSecret)
+	}
+
+	c, err := newOpsmanClient(ctx, f.target, f.user, f.password, f.clientID, f.clientSecret)
+	if err != nil {
+		rp.Fail(errors.Wrapf(err, "connecting to %s", f.target))
+	}
+
+	tl := tile.NewTilesLoader(c, loaderParallelism())
+	ml := manifest.NewManifestsLoader(c, tl, loaderParallelism())
+
+	foundation, err := compare.LoadFoundation(ctx, f.target, tl, ml)
+	if err != nil {
+		rp.Fail(errors.Wrapf(err, "loading %s", f.target))
+	}
+	return foundation
+}
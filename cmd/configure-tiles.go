@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/pivotal-cloudops/omen/internal/configuretiles"
+	"github.com/pivotal-cloudops/omen/internal/tile"
+	"github.com/spf13/cobra"
+)
+
+var configureTilesInputDir string
+var configureTilesNonInteractive bool
+var configureTilesDryRun bool
+var configureTilesShowSecrets bool
+
+var configureTilesCmd = &cobra.Command{
+	Use:   "configure-tiles",
+	Short: "update the staged tile configuration from files",
+	Long:  "Diffs the tile configuration written by staged-tiles against the staged configuration and updates the staged products",
+	Run: func(cmd *cobra.Command, args []string) {
+		if configureTilesInputDir == "" {
+			fmt.Println("an input path is required, please set with the input-dir flag")
+			os.Exit(1)
+		}
+
+		renderOptions, err := diffRenderOptions()
+		if err != nil {
+			rp.Fail(err)
+		}
+
+		ctx := commandContext()
+		client, err := setupOpsmanClient(ctx)
+		if err != nil {
+			rp.Fail(err)
+		}
+		if !configureTilesDryRun {
+			checkForRunningInstallation(ctx, client, rp)
+		}
+
+		tl := tile.NewTilesLoader(client, loaderParallelism())
+
+		options := configuretiles.ConfigureTilesOptions{
+			InputDir:       configureTilesInputDir,
+			NonInteractive: configureTilesNonInteractive,
+			DryRun:         configureTilesDryRun,
+			ShowSecrets:    configureTilesShowSecrets,
+			RenderOptions:  renderOptions,
+		}
+
+		err = configuretiles.NewConfigureTilesOp(tl, client, rp, options).Execute(ctx)
+		if err != nil {
+			rp.Fail(err)
+		}
+	},
+}
+
+func init() {
+	configureTilesCmd.Flags().StringVarP(&configureTilesInputDir, "input-dir", "i", "",
+		"required - the directory of per-product configuration written by staged-tiles")
+
+	configureTilesCmd.Flags().BoolVarP(&configureTilesNonInteractive, "non-interactive", "n", false,
+		"Set this flag to skip user confirmation before updating the staged configuration")
+
+	configureTilesCmd.Flags().BoolVarP(&configureTilesDryRun, "dry-run", "d", false,
+		"Set this flag to display the planned changes only")
+
+	configureTilesCmd.Flags().BoolVar(&configureTilesShowSecrets, "show-secrets", false,
+		"Set this flag to show passwords, keys and certificates in the planned changes instead of redacting them")
+
+	addDiffRenderFlags(configureTilesCmd)
+
+	addPreflightFlags(configureTilesCmd)
+}
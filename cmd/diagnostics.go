@@ -10,8 +10,12 @@ var diagnosticsCmd = &cobra.Command{
 	Use:   "diagnostics",
 	Short: "produce a report of the state of PCF",
 	Run: func(cmd *cobra.Command, args []string) {
-		client := setupOpsmanClient()
-		report, err := client.Get("/api/v0/diagnostic_report", 10*time.Minute)
+		ctx := commandContext()
+		client, err := setupOpsmanClient(ctx)
+		if err != nil {
+			rp.Fail(err)
+		}
+		report, err := client.Get(ctx, "/api/v0/diagnostic_report", 10*time.Minute)
 		if err != nil {
 			rp.Fail(err)
 		}
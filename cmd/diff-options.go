@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+
+	"github.com/pivotal-cloudops/omen/internal/diff"
+	"github.com/spf13/cobra"
+)
+
+const diffIgnoreFile = ".omen-diff-ignore"
+
+var diffStyle string
+var diffContext int
+var noColor bool
+var ignorePaths []string
+var ignoreFile string
+
+func addDiffRenderFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&diffStyle, "diff-style", string(diff.StyleFlat),
+		`(Optional) How to render the text diff: "flat", "unified" or "side-by-side"`)
+
+	cmd.Flags().IntVar(&diffContext, "diff-context", 3,
+		"(Optional) Number of unchanged lines to show around each change in the unified and side-by-side diff styles")
+
+	cmd.Flags().BoolVar(&noColor, "no-color", false,
+		"Set this flag to disable colors in the diff; colors are only used when writing to a terminal")
+
+	cmd.Flags().StringSliceVar(&ignorePaths, "ignore-path", []string{},
+		`(Optional) Glob patterns of diff paths to suppress, in addition to those in the ignore file (e.g. "cf:manifests[*].update.*")`)
+
+	cmd.Flags().StringVar(&ignoreFile, "ignore-file", "",
+		"(Optional) File of diff paths to suppress, one glob pattern per line (defaults to "+diffIgnoreFile+" if it exists)")
+}
+
+func diffRenderOptions() (diff.RenderOptions, error) {
+	style, err := diff.ParseStyle(diffStyle)
+	if err != nil {
+		return diff.RenderOptions{}, err
+	}
+
+	if diffContext < 0 {
+		return diff.RenderOptions{}, errors.New("--diff-context cannot be negative")
+	}
+
+	rules, err := loadIgnoreRules()
+	if err != nil {
+		return diff.RenderOptions{}, err
+	}
+
+	return diff.RenderOptions{
+		Style:   style,
+		Context: diffContext,
+		Color:   !noColor && isTerminal(os.Stdout),
+		Ignore:  rules,
+	}, nil
+}
+
+// loadIgnoreRules reads the --ignore-file, or the diff ignore file in the
+// working directory when there is one, and adds the rules given with
+// --ignore-path.
+func loadIgnoreRules() (diff.IgnoreRules, error) {
+	path := ignoreFile
+	if path == "" {
+		path = diffIgnoreFile
+	}
+
+	rules, err := diff.LoadIgnoreFile(path)
+	if err != nil && (ignoreFile != "" || !os.IsNotExist(err)) {
+		return nil, err
+	}
+
+	for _, p := range ignorePaths {
+		rules = append(rules, diff.ParseIgnoreRule(p))
+	}
+
+	return rules, nil
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
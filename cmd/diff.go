@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/pivotal-cloudops/omen/internal/diff"
+	"github.com/pivotal-cloudops/omen/internal/snapshot"
+	"github.com/spf13/cobra"
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff <snapshot-a> <snapshot-b>",
+	Short: "diff two manifest snapshots saved with manifests --out",
+	Long:  "Compares two snapshots saved with omen manifests --out, without connecting to Ops Manager",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		options, err := diffRenderOptions()
+		if err != nil {
+			rp.Fail(err)
+		}
+
+		a, err := snapshot.Read(args[0])
+		if err != nil {
+			rp.Fail(err)
+		}
+
+		b, err := snapshot.Read(args[1])
+		if err != nil {
+			rp.Fail(err)
+		}
+
+		rp.PrintReport(fmt.Sprintf("--- %s (%s)\n+++ %s (%s)\n", args[0], a.Describe(), args[1], b.Describe()))
+
+		if a.Metadata.Redacted != b.Metadata.Redacted {
+			rp.PrintReport("Warning: only one of the snapshots has its secrets redacted, so every secret shows up as changed")
+		}
+
+		if tiles := snapshot.TileChanges(a, b); tiles != "" {
+			rp.PrintReport("Tile versions:\n" + tiles)
+		}
+
+		d, err := diff.Render(a.Manifests, b.Manifests, options)
+		if err != nil {
+			rp.Fail(err)
+		}
+
+		if d == "" {
+			rp.PrintReport("No differences")
+			return
+		}
+		rp.PrintReport(d)
+	},
+}
+
+func init() {
+	addDiffRenderFlags(diffCmd)
+}
@@ -1,12 +1,12 @@
 package cmd
 
 import (
-	"errors"
-	"fmt"
+	"context"
 
 	"github.com/pivotal-cf/om/api"
 	"github.com/pivotal-cloudops/omen/internal/errands"
 	"github.com/pivotal-cloudops/omen/internal/tile"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
 
@@ -25,15 +25,19 @@ func init() {
 }
 
 var errandsFunc = func(*cobra.Command, []string) {
-	c := setupOpsmanClient()
+	ctx := commandContext()
+	c, err := setupOpsmanClient(ctx)
+	if err != nil {
+		rp.Fail(err)
+	}
 	api := api.New(api.ApiInput{
 		Client: c,
 	})
 	et := errands.NewErrandReporter(api, tr)
-	tl := tile.NewTilesLoader(c)
+	tl := tile.NewTilesLoader(c, loaderParallelism())
 
 	if len(errandProductSlugs) > 0 {
-		guids, err := mapGuid(tl, errandProductSlugs)
+		guids, err := mapGuid(ctx, tl, errandProductSlugs)
 
 		if err != nil {
 			rp.Fail(err)
@@ -45,13 +49,13 @@ var errandsFunc = func(*cobra.Command, []string) {
 			rp.Fail(err)
 		}
 	} else {
-		reportAllErrands(tl, et)
+		reportAllErrands(ctx, tl, et)
 	}
 }
 
-func mapGuid(tl tile.Loader, productSlugs []string) ([]string, error) {
+func mapGuid(ctx context.Context, tl tile.Loader, productSlugs []string) ([]string, error) {
 	var guids []string
-	deployedProducts, err := tl.LoadDeployed(false)
+	deployedProducts, err := tl.LoadDeployed(ctx, false)
 	if err != nil {
 		return nil, err
 	}
@@ -66,10 +70,10 @@ func mapGuid(tl tile.Loader, productSlugs []string) ([]string, error) {
 	return guids, err
 }
 
-func reportAllErrands(tl tile.Loader, er errands.ErrandReporter) {
-	deployedProducts, err := tl.LoadDeployed(false)
+func reportAllErrands(ctx context.Context, tl tile.Loader, er errands.ErrandReporter) {
+	deployedProducts, err := tl.LoadDeployed(ctx, false)
 	if err != nil {
-		rp.Fail(errors.New(fmt.Sprintf("Unable to fetch deployed products:\n%#v", err)))
+		rp.Fail(errors.Wrap(err, "Unable to fetch deployed products"))
 	}
 	for _, product := range deployedProducts.Data {
 		err := er.Execute([]string{product.GUID})
@@ -14,12 +14,16 @@ var listTilesCmd = &cobra.Command{
 }
 
 func listTiles(_ *cobra.Command, _ []string) {
-	client := setupOpsmanClient()
-	tileLoader := tile.NewTilesLoader(client)
+	ctx := commandContext()
+	client, err := setupOpsmanClient(ctx)
+	if err != nil {
+		rp.Fail(err)
+	}
+	tileLoader := tile.NewTilesLoader(client, loaderParallelism())
 	reporter := userio.NewTableReporter()
 	tileLister := tile.NewTileLister(tileLoader, reporter)
 
-	err := tileLister.Execute()
+	err = tileLister.Execute(ctx)
 	if err != nil {
 		rp.Fail(err)
 	}
@@ -1,27 +1,52 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
+	"time"
 
 	"github.com/pivotal-cloudops/omen/internal/manifest"
+	"github.com/pivotal-cloudops/omen/internal/redact"
+	"github.com/pivotal-cloudops/omen/internal/snapshot"
 	"github.com/pivotal-cloudops/omen/internal/tile"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
+var manifestsShowSecrets bool
+var manifestsOut string
+
 var manifestsCmd = &cobra.Command{
 	Use:   "manifests",
 	Short: "get the manifests of all deployments and cloud-config",
 	Run: func(cmd *cobra.Command, args []string) {
-		client := setupOpsmanClient()
-		tileLoader := tile.NewTilesLoader(client)
-		manifestLoader := manifest.NewManifestsLoader(client, tileLoader)
+		ctx := commandContext()
+		client, err := setupOpsmanClient(ctx)
+		if err != nil {
+			rp.Fail(err)
+		}
+		tileLoader := tile.NewTilesLoader(client, loaderParallelism())
+		manifestLoader := manifest.NewManifestsLoader(client, tileLoader, loaderParallelism())
 
-		manifests, err := manifestLoader.LoadAllDeployed()
+		manifests, err := manifestLoader.LoadAllDeployed(ctx)
 		if err != nil {
 			rp.Fail(err)
 		}
 
-		bytes, err := json.MarshalIndent(manifests, "", " ")
+		if manifestsOut != "" {
+			writeSnapshot(ctx, tileLoader, manifests)
+			return
+		}
+
+		var output interface{} = manifests
+		if !manifestsShowSecrets {
+			output, err = redact.Redact(manifests)
+			if err != nil {
+				rp.Fail(err)
+			}
+		}
+
+		bytes, err := json.MarshalIndent(output, "", " ")
 		if err != nil {
 			rp.Fail(err)
 		}
@@ -29,3 +54,27 @@ var manifestsCmd = &cobra.Command{
 		rp.PrintReport(string(bytes))
 	},
 }
+
+func init() {
+	manifestsCmd.Flags().BoolVar(&manifestsShowSecrets, "show-secrets", false,
+		"Set this flag to show passwords, keys and certificates instead of redacting them")
+
+	manifestsCmd.Flags().StringVar(&manifestsOut, "out", "",
+		"(Optional) Path to save a snapshot of the manifests to, for use with omen diff")
+}
+
+func writeSnapshot(ctx context.Context, tileLoader tile.Loader, manifests manifest.Manifests) {
+	tiles, err := tileLoader.LoadDeployed(ctx, false)
+	if err != nil {
+		rp.Fail(err)
+	}
+
+	s := snapshot.New(viper.GetString(keyTarget), time.Now(), tiles, manifests)
+
+	err = s.Write(manifestsOut, manifestsShowSecrets)
+	if err != nil {
+		rp.Fail(err)
+	}
+
+	rp.PrintReport("Saved snapshot of " + s.Describe() + " to " + manifestsOut)
+}
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pivotal-cloudops/omen/internal/installations"
+	"github.com/pivotal-cloudops/omen/internal/opsman"
+	"github.com/spf13/cobra"
+)
+
+var waitForRunning bool
+
+func addPreflightFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&waitForRunning, "wait-for-running", false,
+		"Set this flag to wait for an installation that is already running instead of failing")
+}
+
+type progressPrinter interface {
+	PrintReport(string)
+}
+
+// stderrPrinter prints progress messages to stderr, keeping stdout for the
+// result document of commands with JSON output.
+type stderrPrinter struct{}
+
+func (stderrPrinter) PrintReport(report string) {
+	logger.Infof("%s", strings.TrimSuffix(report, "\n"))
+}
+
+func checkForRunningInstallation(ctx context.Context, c opsman.Client, progress progressPrinter) {
+	check := installations.NewRunningCheck(c, progress, waitForRunning, 10*time.Second)
+	err := check.Check(ctx)
+	if _, ok := err.(installations.RunningError); ok {
+		rp.Fail(fmt.Errorf("%s\nUse --wait-for-running to queue behind it", err))
+	}
+	rp.Fail(err)
+}
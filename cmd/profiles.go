@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/pivotal-cloudops/omen/internal/profiles"
+	"github.com/spf13/cobra"
+)
+
+var profileAuthType string
+var profileCredentialSource string
+
+var profilesCmd = &cobra.Command{
+	Use:   "profiles",
+	Short: "manage the foundation profiles in ~/.omen/config.yml",
+	Long: "Profiles keep the target, authentication, CA certificate and credential source of foundations, " +
+		"to select with --foundation instead of setting them for every command",
+	// Managing profiles doesn't connect to a foundation, so skip applying one.
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		configureLogging()
+	},
+}
+
+var profilesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "list the foundation profiles",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		config := loadProfiles()
+
+		if len(config.Profiles) == 0 {
+			rp.PrintReport("No profiles, add one with omen profiles add")
+			return
+		}
+
+		tr.Write([]byte("Name\tTarget\tAuth\tCredentials\n----\t------\t----\t-----------\n"))
+		for _, name := range config.Names() {
+			p := config.Profiles[name]
+
+			identity := p.Username
+			if p.AuthType == profiles.AuthClient {
+				identity = p.ClientID
+			}
+
+			credentials := p.CredentialSource
+			if credentials == "" {
+				credentials = "flags or environment"
+			}
+
+			tr.Write([]byte(fmt.Sprintf("%s\t%s\t%s %s\t%s\n", name, p.Target, p.AuthType, identity, credentials)))
+		}
+		tr.Flush()
+	},
+}
+
+var profilesAddCmd = &cobra.Command{
+	Use:   "add NAME",
+	Short: "add a foundation profile",
+	Long: "Adds a profile from the --target, --username or --client-id, --ca-cert and --skip-ssl-validation flags. " +
+		"Passwords and client secrets are never stored; give a --credential-source to read them from instead.",
+	Example: "  omen profiles add prod-east -t https://opsman.prod-east.example.com -u admin " +
+		"--ca-cert ~/certs/prod-ca.pem --credential-source env:PROD_EAST_PASSWORD",
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		flags := cmd.Flags()
+		if flags.Changed("password") || flags.Changed("client-secret") {
+			rp.Fail(errors.New("profiles don't store secrets, use --credential-source env:NAME or file:PATH instead"))
+		}
+
+		p := profiles.Profile{CredentialSource: profileCredentialSource}
+		p.Target, _ = flags.GetString("target")
+		p.Username, _ = flags.GetString("username")
+		p.ClientID, _ = flags.GetString("client-id")
+		p.CACert, _ = flags.GetString("ca-cert")
+		p.SkipSSLValidation, _ = flags.GetBool("skip-ssl-validation")
+
+		p.AuthType = profileAuthType
+		if p.AuthType == "" {
+			p.AuthType = profiles.AuthUser
+			if p.ClientID != "" {
+				p.AuthType = profiles.AuthClient
+			}
+		}
+
+		config := loadProfiles()
+		err := config.Add(args[0], p)
+		if err != nil {
+			rp.Fail(err)
+		}
+
+		err = config.Save(profiles.DefaultPath())
+		if err != nil {
+			rp.Fail(err)
+		}
+		rp.PrintReport(fmt.Sprintf("Added profile %s", args[0]))
+	},
+}
+
+var profilesRemoveCmd = &cobra.Command{
+	Use:   "remove NAME",
+	Short: "remove a foundation profile",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		config := loadProfiles()
+		err := config.Remove(args[0])
+		if err != nil {
+			rp.Fail(err)
+		}
+
+		err = config.Save(profiles.DefaultPath())
+		if err != nil {
+			rp.Fail(err)
+		}
+		rp.PrintReport(fmt.Sprintf("Removed profile %s", args[0]))
+	},
+}
+
+func init() {
+	profilesAddCmd.Flags().StringVar(&profileAuthType, "auth-type", "",
+		`(Optional) "user" or "client"; defaults to client when --client-id is given and user otherwise`)
+
+	profilesAddCmd.Flags().StringVar(&profileCredentialSource, "credential-source", "",
+		"(Optional) Where to read the password or client secret from: env:NAME or file:PATH")
+
+	profilesCmd.AddCommand(profilesListCmd)
+	profilesCmd.AddCommand(profilesAddCmd)
+	profilesCmd.AddCommand(profilesRemoveCmd)
+}
+
+func loadProfiles() profiles.Config {
+	config, err := profiles.Load(profiles.DefaultPath())
+	if err != nil {
+		rp.Fail(err)
+	}
+	return config
+}
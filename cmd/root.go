@@ -1,12 +1,20 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/pivotal-cloudops/omen/internal/opsman"
+	"github.com/pivotal-cloudops/omen/internal/profiles"
 	"github.com/pivotal-cloudops/omen/internal/sessions"
 	"github.com/pivotal-cloudops/omen/internal/userio"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -17,6 +25,13 @@ const (
 	envOpsmanPassword     = "OPSMAN_PASSWORD"
 	envOpsmanClientId     = "OPSMAN_CLIENT_ID"
 	envOpsmanClientSecret = "OPSMAN_CLIENT_SECRET"
+	envOpsmanCACert       = "OPSMAN_CA_CERT"
+	envOpsmanSkipSSL      = "OPSMAN_SKIP_SSL_VALIDATION"
+	envOpsmanClientCert   = "OPSMAN_CLIENT_CERT"
+	envOpsmanClientKey    = "OPSMAN_CLIENT_KEY"
+	envOmenTrace          = "OMEN_TRACE"
+	envOmenLogLevel       = "OMEN_LOG_LEVEL"
+	envOmenFoundation     = "OMEN_FOUNDATION"
 
 	keyTarget       = "omTarget"
 	keyUser         = "omUser"
@@ -24,15 +39,36 @@ const (
 	keyClientId     = "omClientID"
 	keyClientSecret = "omClientSecret"
 	keyForceLogout  = "forceLogout"
+	keyParallelism  = "parallelism"
+	keyCacheToken   = "cacheToken"
+	keyCACert       = "omCACert"
+	keySkipSSL      = "omSkipSSLValidation"
+	keyClientCert   = "omClientCert"
+	keyClientKey    = "omClientKey"
+	keyRetries      = "retries"
+	keyRetryBackoff = "retryBackoff"
+	keyTimeout      = "timeout"
+	keyTrace        = "trace"
+	keyLogLevel     = "logLevel"
+	keyFoundation   = "foundation"
+
+	defaultParallelism  = 4
+	defaultRetries      = 3
+	defaultRetryBackoff = time.Second
 )
 
 var rp = userio.ReportPrinter{}
 var tr = userio.NewTableReporter()
+var logger = userio.Logger{Out: os.Stderr, Level: userio.LevelInfo}
 
 var rootCmd = &cobra.Command{
 	Use:   "omen",
 	Short: "omen is a phenomenal supplemental tool to the Pivotal OM CLI",
 	Long:  "omen adds functionality helpful to PCF operators",
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		configureLogging()
+		applyProfile()
+	},
 	Run: func(cmd *cobra.Command, args []string) {
 		fmt.Println("No command to run, use --help for a list of available commands")
 		os.Exit(1)
@@ -42,6 +78,15 @@ var rootCmd = &cobra.Command{
 func init() {
 	var omHost, omUser, omPassword, omClientID, omClientSecret string
 	var forceLogout bool
+	var parallelism int
+	var cacheToken bool
+	var caCert, clientCert, clientKey string
+	var skipSSLValidation bool
+	var retries int
+	var retryBackoff time.Duration
+	var timeout time.Duration
+	var trace, logLevel string
+	var foundation string
 
 	rootCmd.PersistentFlags().StringVarP(&omHost, "target", "t", "",
 		fmt.Sprintf("URL to Opsmanager (Defaults to Env Var $%s)", envOpsmanHost))
@@ -61,6 +106,43 @@ func init() {
 	rootCmd.PersistentFlags().BoolVarP(&forceLogout, "force-logout", "f", false,
 		"(optional) Log all other users out of opsman before attempting action")
 
+	rootCmd.PersistentFlags().IntVar(&parallelism, "parallelism", defaultParallelism,
+		"(optional) Maximum number of concurrent requests to Opsmanager when loading tiles and manifests")
+
+	rootCmd.PersistentFlags().StringVar(&caCert, "ca-cert", "",
+		fmt.Sprintf("(optional) CA certificate, or the path to it, to verify Opsmanager with (Defaults to Env Var $%s)", envOpsmanCACert))
+
+	rootCmd.PersistentFlags().BoolVar(&skipSSLValidation, "skip-ssl-validation", false,
+		fmt.Sprintf("(optional) Connect to Opsmanager without verifying its certificate (Defaults to Env Var $%s)", envOpsmanSkipSSL))
+
+	rootCmd.PersistentFlags().StringVar(&clientCert, "client-cert", "",
+		fmt.Sprintf("(optional) Client certificate, or the path to it, for mutual TLS (Defaults to Env Var $%s)", envOpsmanClientCert))
+
+	rootCmd.PersistentFlags().StringVar(&clientKey, "client-key", "",
+		fmt.Sprintf("(optional) Client private key, or the path to it, for mutual TLS (Defaults to Env Var $%s)", envOpsmanClientKey))
+
+	rootCmd.PersistentFlags().IntVar(&retries, "retries", defaultRetries,
+		"(optional) Number of times to retry requests to Opsmanager that fail with a network or server error")
+
+	rootCmd.PersistentFlags().DurationVar(&retryBackoff, "retry-backoff", defaultRetryBackoff,
+		"(optional) Time to wait before the first retry, doubled for every following retry")
+
+	rootCmd.PersistentFlags().DurationVar(&timeout, "timeout", 0,
+		"(optional) Maximum time the whole command may take, e.g. 15m (no limit by default)")
+
+	rootCmd.PersistentFlags().StringVar(&trace, "trace", "",
+		fmt.Sprintf("(optional) Log every request to Opsmanager, with secrets redacted, to stderr or to the given file (Defaults to Env Var $%s)", envOmenTrace))
+	rootCmd.PersistentFlags().Lookup("trace").NoOptDefVal = "stderr"
+
+	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "info",
+		fmt.Sprintf("(optional) Level of the progress messages to print: error, warn, info or debug (Defaults to Env Var $%s)", envOmenLogLevel))
+
+	rootCmd.PersistentFlags().StringVar(&foundation, "foundation", "",
+		fmt.Sprintf("(optional) Name of the profile in ~/.omen/config.yml to connect with (Defaults to Env Var $%s)", envOmenFoundation))
+
+	rootCmd.PersistentFlags().BoolVar(&cacheToken, "cache-token", false,
+		"(optional) Keep the Opsmanager login token in ~/.omen/tokens to reuse it in later runs")
+
 	_ = viper.BindPFlag(keyTarget, rootCmd.PersistentFlags().Lookup("target"))
 	_ = viper.BindEnv(keyTarget, envOpsmanHost)
 
@@ -76,8 +158,39 @@ func init() {
 	_ = viper.BindPFlag(keyClientSecret, rootCmd.PersistentFlags().Lookup("client-secret"))
 	_ = viper.BindEnv(keyClientSecret, envOpsmanClientSecret)
 
+	_ = viper.BindPFlag(keyCACert, rootCmd.PersistentFlags().Lookup("ca-cert"))
+	_ = viper.BindEnv(keyCACert, envOpsmanCACert)
+
+	_ = viper.BindPFlag(keySkipSSL, rootCmd.PersistentFlags().Lookup("skip-ssl-validation"))
+	_ = viper.BindEnv(keySkipSSL, envOpsmanSkipSSL)
+
+	_ = viper.BindPFlag(keyClientCert, rootCmd.PersistentFlags().Lookup("client-cert"))
+	_ = viper.BindEnv(keyClientCert, envOpsmanClientCert)
+
+	_ = viper.BindPFlag(keyClientKey, rootCmd.PersistentFlags().Lookup("client-key"))
+	_ = viper.BindEnv(keyClientKey, envOpsmanClientKey)
+
 	_ = viper.BindPFlag(keyForceLogout, rootCmd.PersistentFlags().Lookup("force-logout"))
 
+	_ = viper.BindPFlag(keyParallelism, rootCmd.PersistentFlags().Lookup("parallelism"))
+
+	_ = viper.BindPFlag(keyCacheToken, rootCmd.PersistentFlags().Lookup("cache-token"))
+
+	_ = viper.BindPFlag(keyRetries, rootCmd.PersistentFlags().Lookup("retries"))
+
+	_ = viper.BindPFlag(keyRetryBackoff, rootCmd.PersistentFlags().Lookup("retry-backoff"))
+
+	_ = viper.BindPFlag(keyTimeout, rootCmd.PersistentFlags().Lookup("timeout"))
+
+	_ = viper.BindPFlag(keyTrace, rootCmd.PersistentFlags().Lookup("trace"))
+	_ = viper.BindEnv(keyTrace, envOmenTrace)
+
+	_ = viper.BindPFlag(keyLogLevel, rootCmd.PersistentFlags().Lookup("log-level"))
+	_ = viper.BindEnv(keyLogLevel, envOmenLogLevel)
+
+	_ = viper.BindPFlag(keyFoundation, rootCmd.PersistentFlags().Lookup("foundation"))
+	_ = viper.BindEnv(keyFoundation, envOmenFoundation)
+
 	rootCmd.AddCommand(versionCmd)
 	rootCmd.AddCommand(diagnosticsCmd)
 	rootCmd.AddCommand(manifestsCmd)
@@ -88,63 +201,204 @@ func init() {
 	rootCmd.AddCommand(errandsCmd)
 	rootCmd.AddCommand(listTilesCmd)
 	rootCmd.AddCommand(guidCmd)
+	rootCmd.AddCommand(diffCmd)
+	rootCmd.AddCommand(compareCmd)
+	rootCmd.AddCommand(stagedDiffCmd)
+	rootCmd.AddCommand(configureTilesCmd)
+	rootCmd.AddCommand(profilesCmd)
 }
 
 func Execute() {
+	// cobra has already printed the error and the usage to stderr.
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+		os.Exit(userio.ExitCode(err))
+	}
+}
+
+// commandContext returns the context for the requests of a command. It is
+// cancelled once the --timeout has passed or on the first interrupt, so that
+// in-flight requests stop cleanly; a second interrupt exits straight away.
+func commandContext() context.Context {
+	var (
+		ctx    context.Context
+		cancel context.CancelFunc
+	)
+	if timeout := viper.GetDuration(keyTimeout); timeout > 0 {
+		logger.Debugf("Cancelling requests to Opsmanager after %s", timeout)
+		ctx, cancel = context.WithTimeout(context.Background(), timeout)
+	} else {
+		ctx, cancel = context.WithCancel(context.Background())
+	}
+
+	interrupts := make(chan os.Signal, 2)
+	signal.Notify(interrupts, os.Interrupt)
+	go func() {
+		<-interrupts
+		logger.Warnf("Interrupted, cancelling requests to Opsmanager (interrupt again to exit immediately)")
+		cancel()
+
+		<-interrupts
+		os.Exit(130)
+	}()
+
+	return ctx
+}
+
+func configureLogging() {
+	level, err := userio.ParseLogLevel(viper.GetString(keyLogLevel))
+	if err != nil {
+		rp.Fail(err)
 	}
+	logger.Level = level
 }
 
-func setupOpsmanClient() opsman.Client {
-	url := viper.GetString(keyTarget)
-	user := ""
+// applyProfile fills in the connection settings of the --foundation profile
+// that no flag or environment variable has set already. The identity and its
+// secret go together, so a username or client ID given any other way replaces
+// the profile's.
+func applyProfile() {
+	name := viper.GetString(keyFoundation)
+	if name == "" {
+		return
+	}
+
+	config, err := profiles.Load(profiles.DefaultPath())
+	if err != nil {
+		rp.Fail(err)
+	}
+	p, err := config.Get(name)
+	if err != nil {
+		rp.Fail(err)
+	}
+	logger.Debugf("Using the %s profile", name)
+
+	if viper.GetString(keyTarget) == "" {
+		viper.Set(keyTarget, p.Target)
+	}
+	if viper.GetString(keyCACert) == "" {
+		viper.Set(keyCACert, p.CACert)
+	}
+	if p.SkipSSLValidation && !viper.GetBool(keySkipSSL) {
+		viper.Set(keySkipSSL, true)
+	}
+
+	if viper.GetString(keyUser) != "" || viper.GetString(keyClientId) != "" {
+		return
+	}
+
+	identityKey, secretKey := keyUser, keyPassword
+	identity := p.Username
+	if p.AuthType == profiles.AuthClient {
+		identityKey, secretKey = keyClientId, keyClientSecret
+		identity = p.ClientID
+	}
+	viper.Set(identityKey, identity)
+
+	if viper.GetString(secretKey) == "" {
+		secret, err := p.Secret()
+		if err != nil {
+			rp.Fail(fmt.Errorf("profile %s: %s", name, err))
+		}
+		viper.Set(secretKey, secret)
+	}
+}
+
+// traceWriter returns where to trace requests to: nowhere, stderr or a file
+// that every run appends to.
+func traceWriter() io.Writer {
+	trace := viper.GetString(keyTrace)
+	switch strings.ToLower(trace) {
+	case "", "false", "0":
+		return nil
+	case "stderr", "true", "1":
+		return os.Stderr
+	}
+
+	f, err := os.OpenFile(trace, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		rp.Fail(fmt.Errorf("could not open the trace file: %s", err))
+	}
+	return f
+}
+
+func setupOpsmanClient(ctx context.Context) (opsman.Client, error) {
+	return newOpsmanClient(
+		ctx,
+		viper.GetString(keyTarget),
+		viper.GetString(keyUser),
+		viper.GetString(keyPassword),
+		viper.GetString(keyClientId),
+		viper.GetString(keyClientSecret),
+	)
+}
+
+func loaderParallelism() int {
+	return viper.GetInt(keyParallelism)
+}
+
+// newOpsmanClient connects to Ops Manager, logging all other users out first
+// with --force-logout.
+func newOpsmanClient(ctx context.Context, url, user, password, clientID, clientSecret string) (opsman.Client, error) {
 	secret := ""
-	clientID := viper.GetString(keyClientId)
-	clientSecret := viper.GetString(keyClientSecret)
 	forceLogout := viper.GetBool(keyForceLogout)
 
 	if url == "" {
-		fmt.Println("Opsman host is required. Please specify by flag or environment variable")
-		os.Exit(1)
+		return opsman.Client{}, errors.New("Opsman host is required. Please specify by flag or environment variable")
 	}
 
 	if clientID == "" && clientSecret == "" {
-		user = viper.GetString(keyUser)
-		secret = viper.GetString(keyPassword)
+		secret = password
 
 		if user == "" {
-			fmt.Println("Opsman user is required. Please specify by flag or environment variable")
-			os.Exit(1)
+			return opsman.Client{}, errors.New("Opsman user is required. Please specify by flag or environment variable")
 		}
 
 		if secret == "" {
-			fmt.Println("Opsman user secret is required. Please specify by flag or environment variable")
-			os.Exit(1)
+			return opsman.Client{}, errors.New("Opsman user secret is required. Please specify by flag or environment variable")
 		}
 	} else {
+		user = ""
+
 		if clientID == "" {
-			fmt.Println("Opsman client ID is required. Please specify by flag or environment variable")
-			os.Exit(1)
+			return opsman.Client{}, errors.New("Opsman client ID is required. Please specify by flag or environment variable")
 		}
 
 		if clientSecret == "" {
-			fmt.Println("Opsman client secret is required. Please specify by flag or environment variable")
-			os.Exit(1)
+			return opsman.Client{}, errors.New("Opsman client secret is required. Please specify by flag or environment variable")
 		}
 	}
 
-	client := opsman.NewClient(url, user, secret, clientID, clientSecret)
+	options := opsman.ClientOptions{
+		CACert:            viper.GetString(keyCACert),
+		SkipSSLValidation: viper.GetBool(keySkipSSL),
+		ClientCert:        viper.GetString(keyClientCert),
+		ClientKey:         viper.GetString(keyClientKey),
+		Retries:           viper.GetInt(keyRetries),
+		RetryBackoff:      viper.GetDuration(keyRetryBackoff),
+		Trace:             traceWriter(),
+	}
+	if viper.GetBool(keyCacheToken) {
+		options.TokenCacheDir = filepath.Join(os.Getenv("HOME"), ".omen", "tokens")
+	}
+
+	if user != "" {
+		logger.Debugf("Connecting to %s as %s", url, user)
+	} else {
+		logger.Debugf("Connecting to %s with client %s", url, clientID)
+	}
+
+	client, err := opsman.NewClient(url, user, secret, clientID, clientSecret, options)
+	if err != nil {
+		return opsman.Client{}, err
+	}
 
 	if forceLogout == true {
-		fmt.Println("Logging out all active opsman sessions.")
-		err := sessions.NewSessionManager(client).ClearAll()
+		logger.Infof("Logging out all active opsman sessions.")
+		err := sessions.NewSessionManager(client).ClearAll(ctx)
 		if err != nil {
-			fmt.Println("Failed to clear sessions with error: ", err.Error())
-			os.Exit(1)
+			return opsman.Client{}, errors.Wrap(err, "Failed to clear sessions")
 		}
 	}
 
-	return client
+	return client, nil
 }
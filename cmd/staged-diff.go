@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/pivotal-cloudops/omen/internal/configdiff"
+	"github.com/pivotal-cloudops/omen/internal/tile"
+	"github.com/spf13/cobra"
+)
+
+var stagedDiffProducts string
+var stagedDiffShowSecrets bool
+
+var stagedDiffCmd = &cobra.Command{
+	Use:   "staged-diff",
+	Short: "diff the deployed and staged configuration of tiles",
+	Long:  "Compares the deployed and staged properties, resources, networks and errands of each tile",
+	Run: func(cmd *cobra.Command, args []string) {
+		options, err := diffRenderOptions()
+		if err != nil {
+			rp.Fail(err)
+		}
+
+		ctx := commandContext()
+		client, err := setupOpsmanClient(ctx)
+		if err != nil {
+			rp.Fail(err)
+		}
+		tl := tile.NewTilesLoader(client, loaderParallelism())
+
+		var guids []string
+		if stagedDiffProducts != "" {
+			tiles, err := tl.LoadStaged(ctx, false)
+			if err != nil {
+				rp.Fail(err)
+			}
+
+			selected, err := tiles.FindBySlugsOrGUIDs(strings.Split(stagedDiffProducts, ","))
+			if err != nil {
+				rp.Fail(err)
+			}
+
+			for _, t := range selected {
+				guids = append(guids, t.GUID)
+			}
+		}
+
+		d, err := configdiff.NewDiffer(tl, options, stagedDiffShowSecrets).Diff(ctx, guids)
+		if err != nil {
+			rp.Fail(err)
+		}
+
+		if d == "" {
+			rp.PrintReport("No tile configuration changes")
+			return
+		}
+		rp.PrintReport(d)
+	},
+}
+
+func init() {
+	stagedDiffCmd.Flags().StringVarP(&stagedDiffProducts, "products", "P", "",
+		`Optional flag to set the products to diff (e.g. "product-1" or "product-1,product-2")`)
+
+	stagedDiffCmd.Flags().BoolVar(&stagedDiffShowSecrets, "show-secrets", false,
+		"Set this flag to show passwords, keys and certificates instead of redacting them")
+
+	addDiffRenderFlags(stagedDiffCmd)
+}
@@ -17,15 +17,27 @@ var stagedTilesCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
-		client := setupOpsmanClient()
-		tileLoader := tile.NewTilesLoader(client)
+		if stagedTilesFormat != "json" && stagedTilesFormat != "om-config" {
+			rp.Fail(fmt.Errorf("unknown format %q, expected json or om-config", stagedTilesFormat))
+		}
+
+		ctx := commandContext()
+		client, err := setupOpsmanClient(ctx)
+		if err != nil {
+			rp.Fail(err)
+		}
+		tileLoader := tile.NewTilesLoader(client, loaderParallelism())
 
-		tiles, err := tileLoader.LoadStaged(true)
+		tiles, err := tileLoader.LoadStaged(ctx, true)
 		if err != nil {
 			rp.Fail(err)
 		}
 
-		err = tiles.Write(outputDir)
+		if stagedTilesFormat == "om-config" {
+			err = tiles.WriteOMConfig(outputDir, includePlaceholders)
+		} else {
+			err = tiles.Write(outputDir)
+		}
 		if err != nil {
 			rp.Fail(err)
 		}
@@ -35,7 +47,15 @@ var stagedTilesCmd = &cobra.Command{
 }
 
 var outputDir string
+var stagedTilesFormat string
+var includePlaceholders bool
 
 func init() {
 	stagedTilesCmd.Flags().StringVarP(&outputDir, "output-dir", "o", "", "required - sets where to put the files generated by this command")
+
+	stagedTilesCmd.Flags().StringVar(&stagedTilesFormat, "format", "json",
+		"Set the format of the files: json (the raw API responses) or om-config (one om configure-product YAML per product)")
+
+	stagedTilesCmd.Flags().BoolVar(&includePlaceholders, "include-placeholders", false,
+		"Set this flag to write credentials as ((vars)) placeholders in om-config files instead of leaving them out")
 }
@@ -14,9 +14,13 @@ var stemcellUpdatesCmd = &cobra.Command{
 }
 
 var stemcellUpdatesFunc = func(*cobra.Command, []string) {
-	c := setupOpsmanClient()
+	ctx := commandContext()
+	c, err := setupOpsmanClient(ctx)
+	if err != nil {
+		rp.Fail(err)
+	}
 	sd := stemcelldiff.NewStemcellUpdateDetector(c, rp)
-	err := sd.DetectMissingStemcells()
+	err = sd.DetectMissingStemcells(ctx)
 	if err != nil {
 		rp.Fail(err)
 	}
@@ -5,7 +5,6 @@ import (
 	"github.com/pivotal-cloudops/omen/internal/tile"
 	"github.com/pivotal-cloudops/omen/internal/tileguid"
 	"github.com/spf13/cobra"
-	"os"
 )
 
 var guidCmd = &cobra.Command{
@@ -16,13 +15,16 @@ var guidCmd = &cobra.Command{
 }
 
 func tileGuid(_ *cobra.Command, args []string) {
-	client := setupOpsmanClient()
-	tileLoader := tile.NewTilesLoader(client)
+	ctx := commandContext()
+	client, err := setupOpsmanClient(ctx)
+	if err != nil {
+		rp.Fail(err)
+	}
+	tileLoader := tile.NewTilesLoader(client, loaderParallelism())
 
-	guid, err := tileguid.FindGuid(tileLoader, args[0])
+	guid, err := tileguid.FindGuid(ctx, tileLoader, args[0])
 	if err != nil {
-		fmt.Println(err.Error())
-		os.Exit(1)
+		rp.Fail(err)
 	}
 
 	fmt.Println(guid)
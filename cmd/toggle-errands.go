@@ -1,13 +1,14 @@
 package cmd
 
 import (
-	"errors"
+	"context"
 	"fmt"
 	"strings"
 
 	"github.com/pivotal-cf/om/api"
 	"github.com/pivotal-cloudops/omen/internal/errands"
 	"github.com/pivotal-cloudops/omen/internal/tile"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
 
@@ -37,11 +38,19 @@ func init() {
 
 	toggleErrandsCmd.Flags().StringSliceVar(&toggleErrandProducts, "products", []string{},
 		`(Optional) A comma-delimited list of product guids or slugs (e.g. p-redis) for errand updates. When omitted, all products will be affected.`)
+
+	addPreflightFlags(toggleErrandsCmd)
 }
 
 var toggleErrandsFunc = func(*cobra.Command, []string) {
 	validateFlags()
-	c := setupOpsmanClient()
+	ctx := commandContext()
+	c, err := setupOpsmanClient(ctx)
+	if err != nil {
+		rp.Fail(err)
+	}
+	checkForRunningInstallation(ctx, c, rp)
+
 	es := api.New(api.ApiInput{
 		Client: c,
 	})
@@ -54,19 +63,19 @@ var toggleErrandsFunc = func(*cobra.Command, []string) {
 
 	rep := fmt.Sprintf("Action: %s, Errand-Type: %s, Products: %s", errandAction, errandType, products)
 	rp.PrintReport(rep)
-	tl := tile.NewTilesLoader(c)
+	tl := tile.NewTilesLoader(c, loaderParallelism())
 
 	if len(toggleErrandProducts) > 0 {
-		toggleErrandsForProducts(tl, et, toggleErrandProducts)
+		toggleErrandsForProducts(ctx, tl, et, toggleErrandProducts)
 	} else {
-		toggleAllErrands(tl, et)
+		toggleAllErrands(ctx, tl, et)
 	}
 }
 
-func toggleErrandsForProducts(tl tile.Loader, et errands.ErrandToggler, products []string) {
-	tiles, err := tl.LoadDeployed(false)
+func toggleErrandsForProducts(ctx context.Context, tl tile.Loader, et errands.ErrandToggler, products []string) {
+	tiles, err := tl.LoadDeployed(ctx, false)
 	if err != nil {
-		rp.Fail(errors.New(fmt.Sprintf("Unable to fetch deployed products:\n%#v", err)))
+		rp.Fail(errors.Wrap(err, "Unable to fetch deployed products"))
 	}
 
 	tileGUIDs := mapProductNamesOrGUIDsToGUIDs(tiles, products)
@@ -81,7 +90,7 @@ func toggleErrandsForProducts(tl tile.Loader, et errands.ErrandToggler, products
 func mapProductNamesOrGUIDsToGUIDs(tiles tile.Tiles, products []string) []string {
 	foundTiles, err := tiles.FindBySlugsOrGUIDs(products)
 	if err != nil {
-		rp.Fail(errors.New(fmt.Sprintf("Unable to find products:\n%#v", err)))
+		rp.Fail(errors.Wrap(err, "Unable to find products"))
 	}
 	tileGUIDs := make([]string, 0)
 	for _, foundTile := range foundTiles {
@@ -100,10 +109,10 @@ func newErrandToggler(api api.Api) errands.ErrandToggler {
 	return et
 }
 
-func toggleAllErrands(tl tile.Loader, et errands.ErrandToggler) {
-	deployedProducts, err := tl.LoadDeployed(false)
+func toggleAllErrands(ctx context.Context, tl tile.Loader, et errands.ErrandToggler) {
+	deployedProducts, err := tl.LoadDeployed(ctx, false)
 	if err != nil {
-		rp.Fail(errors.New(fmt.Sprintf("Unable to fetch deployed products:\n%#v", err)))
+		rp.Fail(errors.Wrap(err, "Unable to fetch deployed products"))
 	}
 	for _, product := range deployedProducts.Data {
 		err := et.Execute([]string{product.GUID})
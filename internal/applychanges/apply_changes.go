@@ -1,39 +1,66 @@
 package applychanges
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"time"
 
+	"github.com/pkg/errors"
+
 	"github.com/pivotal-cloudops/omen/internal/diff"
+	"github.com/pivotal-cloudops/omen/internal/installations"
 	"github.com/pivotal-cloudops/omen/internal/manifest"
+	"github.com/pivotal-cloudops/omen/internal/predeploy"
+	"github.com/pivotal-cloudops/omen/internal/redact"
+	"github.com/pivotal-cloudops/omen/internal/risk"
 	"github.com/pivotal-cloudops/omen/internal/tile"
 	"github.com/pivotal-cloudops/omen/internal/userio"
 )
 
 type applyChangesBody struct {
-	IgnoreWarnings bool        `json:"ignore_warnings"`
-	DeployProducts interface{} `json:"deploy_products"`
+	IgnoreWarnings bool                      `json:"ignore_warnings"`
+	DeployProducts interface{}               `json:"deploy_products"`
+	Errands        map[string]productErrands `json:"errands,omitempty"`
+}
+
+type installResponse struct {
+	Install struct {
+		ID int `json:"id"`
+	} `json:"install"`
 }
 
 type ApplyChangesOptions struct {
-	TileSlugs      []string
-	NonInteractive bool
-	DryRun         bool
-	Quiet          bool
+	TileSlugs         []string
+	NonInteractive    bool
+	DryRun            bool
+	Quiet             bool
+	Wait              bool
+	PlanFile          string
+	PlanOutput        string
+	IgnoreWarnings    bool
+	ErrandOverrides   []ErrandOverride
+	OutputFormat      string
+	DiffFormat        string
+	ShowSecrets       bool
+	DiffStyle         diff.Style
+	DiffContext       int
+	Color             bool
+	IgnoreRules       diff.IgnoreRules
+	IncludeConfigDiff bool
 }
 
 //go:generate counterfeiter . manifestsLoader
 type manifestsLoader interface {
-	LoadAllDeployed() (manifest.Manifests, error)
-	LoadAllStaged() (manifest.Manifests, error)
-	LoadDeployed(tileGuids []string) (manifest.Manifests, error)
-	LoadStaged(tileGuids []string) (manifest.Manifests, error)
+	LoadAllDeployed(ctx context.Context) (manifest.Manifests, error)
+	LoadAllStaged(ctx context.Context) (manifest.Manifests, error)
+	LoadDeployed(ctx context.Context, tileGuids []string) (manifest.Manifests, error)
+	LoadStaged(ctx context.Context, tileGuids []string) (manifest.Manifests, error)
 }
 
 type tilesLoader interface {
-	LoadStaged(bool) (tile.Tiles, error)
-	LoadDeployed(bool) (tile.Tiles, error)
+	LoadStaged(context.Context, bool) (tile.Tiles, error)
+	LoadDeployed(context.Context, bool) (tile.Tiles, error)
 }
 
 //go:generate counterfeiter . reportPrinter
@@ -43,53 +70,160 @@ type reportPrinter interface {
 
 //go:generate counterfeiter . opsmanClient
 type opsmanClient interface {
-	Post(endpoint, data string, timeout time.Duration) ([]byte, error)
+	Get(ctx context.Context, endpoint string, timeout time.Duration) ([]byte, error)
+	Post(ctx context.Context, endpoint, data string, timeout time.Duration) ([]byte, error)
+}
+
+//go:generate counterfeiter . installationWatcher
+type installationWatcher interface {
+	Wait(ctx context.Context, id int) (installations.Installation, error)
+}
+
+//go:generate counterfeiter . preDeployChecker
+type preDeployChecker interface {
+	Check(ctx context.Context, tileGuids []string) (predeploy.Report, error)
+}
+
+//go:generate counterfeiter . configDiffer
+type configDiffer interface {
+	Diff(ctx context.Context, tileGuids []string) (string, error)
 }
 
 type ApplyChangesOp interface {
-	Execute() error
+	Execute(ctx context.Context) error
 }
 
 type applyChangesOp struct {
-	manifestsLoader manifestsLoader
-	tilesLoader     tilesLoader
-	opsmanClient    opsmanClient
-	reportPrinter   reportPrinter
-	options         ApplyChangesOptions
+	manifestsLoader     manifestsLoader
+	tilesLoader         tilesLoader
+	opsmanClient        opsmanClient
+	reportPrinter       reportPrinter
+	installationWatcher installationWatcher
+	preDeployChecker    preDeployChecker
+	configDiffer        configDiffer
+	options             ApplyChangesOptions
+	result              Result
+	resultPrinted       bool
 }
 
-func NewApplyChangesOp(ml manifestsLoader, tl tilesLoader, c opsmanClient, rp reportPrinter, options ApplyChangesOptions) ApplyChangesOp {
+func NewApplyChangesOp(ml manifestsLoader, tl tilesLoader, c opsmanClient, rp reportPrinter, iw installationWatcher, pc preDeployChecker, cd configDiffer, options ApplyChangesOptions) ApplyChangesOp {
 	return &applyChangesOp{
-		manifestsLoader: ml,
-		tilesLoader:     tl,
-		opsmanClient:    c,
-		reportPrinter:   rp,
-		options:         options,
+		manifestsLoader:     ml,
+		tilesLoader:         tl,
+		opsmanClient:        c,
+		reportPrinter:       rp,
+		installationWatcher: iw,
+		preDeployChecker:    pc,
+		configDiffer:        cd,
+		options:             options,
 	}
 }
 
-func (a *applyChangesOp) Execute() error {
-	tileGuids, err := a.slugsToGuids()
+func (a *applyChangesOp) Execute(ctx context.Context) error {
+	err := a.execute(ctx)
+	if err != nil && a.isJSON() && !a.resultPrinted {
+		a.result.Error = err.Error()
+		if printErr := a.printResult(StatusFailed); printErr != nil {
+			return printErr
+		}
+	}
+	return err
+}
+
+func (a *applyChangesOp) execute(ctx context.Context) error {
+	var (
+		plan      Plan
+		tileGuids []string
+		staged    manifest.Manifests
+		err       error
+	)
+
+	if a.options.PlanFile != "" {
+		plan, err = ReadPlan(a.options.PlanFile)
+		if err != nil {
+			return err
+		}
+		tileGuids = plan.TileGuids
+	} else {
+		tileGuids, err = a.slugsToGuids(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	errands, err := a.errandsBody(ctx, tileGuids)
 	if err != nil {
 		return err
 	}
 
-	if a.shouldPrintOutput() {
-		manifestDiff, err := a.makeDiff(tileGuids)
+	if a.needsStagedManifests() {
+		staged, err = a.loadStaged(ctx, tileGuids)
+		if err != nil {
+			return err
+		}
+	}
+
+	if a.options.PlanFile != "" {
+		err = a.verifyPlan(ctx, plan, tileGuids, staged)
+		if err != nil {
+			return err
+		}
+	}
+
+	if a.isJSON() {
+		a.result.Products, err = a.resultProducts(ctx, tileGuids)
+		if err != nil {
+			return err
+		}
+	}
+
+	if a.shouldPrintOutput() || a.isJSON() {
+		before, after, err := a.diffInputs(ctx, tileGuids, staged)
+		if err != nil {
+			return err
+		}
+
+		manifestDiff, summary, err := a.makeDiff(before, after)
+
+		if err != nil {
+			return err
+		}
+
+		a.result.Diff = summary
+
+		err = a.printRisk(before, after)
+		if err != nil {
+			return err
+		}
 
+		a.printDiff(manifestDiff)
+
+		err = a.printErrands(ctx, tileGuids)
 		if err != nil {
-			fmt.Println(err)
 			return err
 		}
 
-		if len(manifestDiff) > 0 {
-			a.reportPrinter.PrintReport(manifestDiff)
-		} else if a.isNotADryRun() {
-			a.reportPrinter.PrintReport("Warning: Opsman has detected no pending changes")
+		err = a.printConfigDiff(ctx, tileGuids)
+		if err != nil {
+			return err
 		}
 	}
 
+	err = a.validate(ctx, tileGuids)
+	if err != nil {
+		return err
+	}
+
 	if a.options.DryRun {
+		if a.options.PlanOutput != "" {
+			err = a.writePlan(ctx, tileGuids, staged)
+			if err != nil {
+				return err
+			}
+		}
+		if a.isJSON() {
+			return a.printResult(StatusDryRun)
+		}
 		return nil
 	}
 
@@ -104,7 +238,24 @@ func (a *applyChangesOp) Execute() error {
 		fmt.Println("Applying changes")
 	}
 
-	return a.applyChanges(tileGuids)
+	if ctx.Err() != nil {
+		return errors.Wrap(ctx.Err(), "apply changes was not started")
+	}
+
+	// Verify the plan again, since changes may have been staged while
+	// waiting for confirmation.
+	if a.options.PlanFile != "" {
+		staged, err = a.loadStaged(ctx, tileGuids)
+		if err != nil {
+			return err
+		}
+		err = a.verifyPlan(ctx, plan, tileGuids, staged)
+		if err != nil {
+			return err
+		}
+	}
+
+	return a.applyChanges(ctx, tileGuids, errands)
 }
 
 func (a *applyChangesOp) isInteractive() bool {
@@ -112,14 +263,121 @@ func (a *applyChangesOp) isInteractive() bool {
 }
 
 func (a *applyChangesOp) shouldPrintOutput() bool {
-	return a.options.Quiet == false
+	return a.options.Quiet == false && !a.isJSON()
 }
 
 func (a *applyChangesOp) isNotADryRun() bool {
 	return a.options.DryRun == false
 }
 
-func (a *applyChangesOp) applyChanges(tileGuids []string) error {
+func (a *applyChangesOp) printDiff(manifestDiff string) {
+	if !a.shouldPrintOutput() {
+		return
+	}
+
+	if len(manifestDiff) > 0 {
+		a.reportPrinter.PrintReport(manifestDiff)
+	} else if a.isNotADryRun() {
+		a.reportPrinter.PrintReport("Warning: Opsman has detected no pending changes")
+	}
+}
+
+func (a *applyChangesOp) printErrands(ctx context.Context, tileGuids []string) error {
+	if !a.shouldPrintOutput() {
+		return nil
+	}
+
+	report, err := a.errandsReport(ctx, tileGuids)
+	if err != nil {
+		return err
+	}
+	if report != "" {
+		a.reportPrinter.PrintReport(report)
+	}
+	return nil
+}
+
+func (a *applyChangesOp) validate(ctx context.Context, tileGuids []string) error {
+	report, err := a.preDeployChecker.Check(ctx, tileGuids)
+	if err != nil {
+		return err
+	}
+
+	if report.HasErrors() {
+		return fmt.Errorf("pre-flight validation found blocking errors:\n%s", report)
+	}
+
+	if !report.HasWarnings() {
+		return nil
+	}
+
+	a.result.Warnings = report.Warnings()
+	if !a.options.IgnoreWarnings {
+		return fmt.Errorf("pre-flight validation found verifier warnings, "+
+			"which Ops Manager refuses to apply changes with; pass --ignore-warnings to apply anyway:\n%s", report)
+	}
+
+	if !a.isJSON() {
+		a.reportPrinter.PrintReport(fmt.Sprintf("Pre-flight validation warnings, ignored:\n%s", report))
+	}
+
+	return nil
+}
+
+func (a *applyChangesOp) needsStagedManifests() bool {
+	return a.shouldPrintOutput() || a.isJSON() || a.options.PlanFile != "" || a.options.PlanOutput != ""
+}
+
+func (a *applyChangesOp) writePlan(ctx context.Context, tileGuids []string, staged manifest.Manifests) error {
+	config, err := a.stagedConfig(ctx, tileGuids)
+	if err != nil {
+		return err
+	}
+
+	plan, err := NewPlan(tileGuids, staged, config)
+	if err != nil {
+		return err
+	}
+
+	err = plan.Write(a.options.PlanOutput)
+	if err != nil {
+		return err
+	}
+
+	if a.shouldPrintOutput() {
+		a.reportPrinter.PrintReport(fmt.Sprintf("Plan written to %s", a.options.PlanOutput))
+	}
+	return nil
+}
+
+func (a *applyChangesOp) verifyPlan(ctx context.Context, plan Plan, tileGuids []string, staged manifest.Manifests) error {
+	config, err := a.stagedConfig(ctx, tileGuids)
+	if err != nil {
+		return err
+	}
+	return plan.Verify(staged, config)
+}
+
+// stagedConfig returns the staged tiles being applied with their
+// configuration, all of them when no GUIDs are given.
+func (a *applyChangesOp) stagedConfig(ctx context.Context, tileGuids []string) (tile.Tiles, error) {
+	tiles, err := a.tilesLoader.LoadStaged(ctx, true)
+	if err != nil || len(tileGuids) == 0 {
+		return tiles, err
+	}
+
+	selected := tile.Tiles{}
+	for _, t := range tiles.Data {
+		for _, guid := range tileGuids {
+			if t.GUID == guid {
+				selected.Data = append(selected.Data, t)
+			}
+		}
+	}
+	return selected, nil
+}
+
+func (a *applyChangesOp) applyChanges(ctx context.Context, tileGuids []string, errands map[string]productErrands) error {
 	var guids interface{}
 	if len(tileGuids) == 0 {
 		guids = "all"
@@ -127,32 +385,93 @@ func (a *applyChangesOp) applyChanges(tileGuids []string) error {
 		guids = tileGuids
 	}
 
-	bytes, err := json.Marshal(applyChangesBody{DeployProducts: guids, IgnoreWarnings: true})
+	bytes, err := json.Marshal(applyChangesBody{
+		DeployProducts: guids,
+		IgnoreWarnings: a.options.IgnoreWarnings,
+		Errands:        errands,
+	})
 	if err != nil {
 		return err
 	}
 	body := string(bytes)
 
-	resp, err := a.opsmanClient.Post("/api/v0/installations", body, 10*time.Minute)
+	a.result.StartedAt = now()
+	resp, err := a.opsmanClient.Post(ctx, "/api/v0/installations", body, 10*time.Minute)
+	if err != nil && ctx.Err() != nil {
+		return errors.Wrap(err, "interrupted while starting apply changes; Ops Manager may have started the installation anyway, check its status before retrying")
+	}
 	if err != nil {
-		fmt.Printf("An error occurred applying changes: %v \n", err)
+		if !a.isJSON() {
+			fmt.Printf("An error occurred applying changes: %v \n", err)
+		}
 		return err
 	}
 
-	if a.options.Quiet {
+	if a.isJSON() {
+		var ir installResponse
+		err = json.Unmarshal(resp, &ir)
+		if err != nil {
+			return err
+		}
+		a.result.InstallationID = ir.Install.ID
+	} else if a.options.Quiet {
 		a.reportPrinter.PrintReport(string(resp))
 	} else {
 		a.reportPrinter.PrintReport(fmt.Sprintf("Successfully applied changes: %s \n", string(resp)))
 	}
+
+	if a.options.Wait {
+		return a.waitForInstallation(ctx, resp)
+	}
+
+	if a.isJSON() {
+		return a.printResult(StatusTriggered)
+	}
 	return nil
 }
 
-func (a *applyChangesOp) slugsToGuids() ([]string, error) {
+func (a *applyChangesOp) waitForInstallation(ctx context.Context, resp []byte) error {
+	var ir installResponse
+	err := json.Unmarshal(resp, &ir)
+	if err != nil {
+		return err
+	}
+
+	installation, waitErr := a.installationWatcher.Wait(ctx, ir.Install.ID)
+	if waitErr != nil && ctx.Err() != nil {
+		waitErr = errors.Wrapf(waitErr, "stopped waiting for installation %d, which keeps running in Ops Manager", ir.Install.ID)
+	}
+
+	if a.isJSON() {
+		a.result.FinishedAt = now()
+		if installation.Status == "" {
+			installation.Status = StatusUnknown
+		}
+		if waitErr != nil {
+			a.result.Error = waitErr.Error()
+		}
+		err = a.printResult(installation.Status)
+		if err != nil {
+			return err
+		}
+	}
+
+	if waitErr != nil {
+		return waitErr
+	}
+
+	if a.shouldPrintOutput() {
+		a.reportPrinter.PrintReport(fmt.Sprintf("Installation %d %s", installation.ID, installation.Status))
+	}
+	return nil
+}
+
+func (a *applyChangesOp) slugsToGuids(ctx context.Context) ([]string, error) {
 	if len(a.options.TileSlugs) == 0 {
 		return []string{}, nil
 	}
 
-	tiles, err := a.tilesLoader.LoadStaged(false)
+	tiles, err := a.tilesLoader.LoadStaged(ctx, false)
 	if err != nil {
 		return nil, err
 	}
@@ -167,38 +486,127 @@ func (a *applyChangesOp) slugsToGuids() ([]string, error) {
 	return resp, nil
 }
 
-func (a *applyChangesOp) makeDiff(tileGuids []string) (string, error) {
-	var (
-		manifestA manifest.Manifests
-		manifestB manifest.Manifests
-		err       error
-	)
+func (a *applyChangesOp) loadStaged(ctx context.Context, tileGuids []string) (manifest.Manifests, error) {
+	if len(tileGuids) == 0 {
+		return a.manifestsLoader.LoadAllStaged(ctx)
+	}
+	return a.manifestsLoader.LoadStaged(ctx, tileGuids)
+}
 
+func (a *applyChangesOp) loadDeployed(ctx context.Context, tileGuids []string) (manifest.Manifests, error) {
 	if len(tileGuids) == 0 {
-		manifestA, err = a.manifestsLoader.LoadAllDeployed()
-	} else {
-		manifestA, err = a.manifestsLoader.LoadDeployed(tileGuids)
+		return a.manifestsLoader.LoadAllDeployed(ctx)
+	}
+	return a.manifestsLoader.LoadDeployed(ctx, tileGuids)
+}
+
+// diffInputs returns the deployed and staged manifests to compare, with their
+// secrets redacted unless asked otherwise.
+func (a *applyChangesOp) diffInputs(ctx context.Context, tileGuids []string, staged manifest.Manifests) (interface{}, interface{}, error) {
+	deployed, err := a.loadDeployed(ctx, tileGuids)
+	if err != nil {
+		return nil, nil, err
 	}
 
+	if a.options.ShowSecrets {
+		return deployed, staged, nil
+	}
+
+	before, err := redact.Redact(deployed)
 	if err != nil {
-		return "", err
+		return nil, nil, err
 	}
 
-	if len(tileGuids) == 0 {
-		manifestB, err = a.manifestsLoader.LoadAllStaged()
+	after, err := redact.Redact(staged)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return before, after, nil
+}
+
+func (a *applyChangesOp) makeDiff(before interface{}, after interface{}) (string, DiffSummary, error) {
+	if a.options.DiffFormat == DiffFormatJSON {
+		return structuredDiff(before, after, a.options.IgnoreRules)
+	}
+
+	d, err := diff.Render(before, after, a.renderOptions())
+
+	if err != nil {
+		return "", DiffSummary{}, err
+	}
+
+	summary, err := diff.Summarize(before, after, a.options.IgnoreRules)
+	if err != nil {
+		return "", DiffSummary{}, err
+	}
+
+	return d, DiffSummary{Additions: summary.Additions, Removals: summary.Removals, Suppressed: summary.Suppressed}, nil
+}
+
+func (a *applyChangesOp) printConfigDiff(ctx context.Context, tileGuids []string) error {
+	if !a.shouldPrintOutput() || !a.options.IncludeConfigDiff {
+		return nil
+	}
+
+	d, err := a.configDiffer.Diff(ctx, tileGuids)
+	if err != nil {
+		return err
+	}
+
+	if d == "" {
+		a.reportPrinter.PrintReport("No tile configuration changes")
 	} else {
-		manifestB, err = a.manifestsLoader.LoadStaged(tileGuids)
+		a.reportPrinter.PrintReport("Tile configuration changes:\n" + d)
 	}
+	return nil
+}
 
+func (a *applyChangesOp) printRisk(before interface{}, after interface{}) error {
+	if !a.shouldPrintOutput() || a.options.DiffFormat == DiffFormatJSON {
+		return nil
+	}
+
+	products, err := risk.Assess(before, after, a.options.IgnoreRules)
 	if err != nil {
-		return "", err
+		return err
 	}
 
-	d, err := diff.FlatDiff(manifestA, manifestB)
+	if table := risk.Table(products); table != "" {
+		a.reportPrinter.PrintReport(table)
+	}
+	return nil
+}
+
+func (a *applyChangesOp) renderOptions() diff.RenderOptions {
+	if a.isJSON() {
+		return diff.RenderOptions{Style: diff.StyleFlat, Ignore: a.options.IgnoreRules}
+	}
+	return diff.RenderOptions{
+		Style:   a.options.DiffStyle,
+		Context: a.options.DiffContext,
+		Color:   a.options.Color,
+		Ignore:  a.options.IgnoreRules,
+	}
+}
+
+func structuredDiff(deployed interface{}, staged interface{}, ignore diff.IgnoreRules) (string, DiffSummary, error) {
+	changes, suppressed, err := diff.Structured(deployed, staged, ignore)
+	if err != nil {
+		return "", DiffSummary{}, err
+	}
+
+	summary := summarizeChanges(changes)
+	summary.Suppressed = suppressed
+
+	if len(changes) == 0 {
+		return "", summary, nil
+	}
 
+	b, err := json.MarshalIndent(changes, "", "  ")
 	if err != nil {
-		return "", err
+		return "", DiffSummary{}, err
 	}
 
-	return d, err
+	return string(b), summary, nil
 }
@@ -4,14 +4,23 @@ import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
+	"context"
+	"encoding/json"
 	"errors"
 	"io/ioutil"
+	"os"
+	"path/filepath"
 	"reflect"
+	"time"
 
 	"github.com/pivotal-cloudops/omen/internal/applychanges"
 	"github.com/pivotal-cloudops/omen/internal/applychanges/applychangesfakes"
+	"github.com/pivotal-cloudops/omen/internal/diff"
 	"github.com/pivotal-cloudops/omen/internal/fakes"
+	"github.com/pivotal-cloudops/omen/internal/installations"
 	"github.com/pivotal-cloudops/omen/internal/manifest"
+	"github.com/pivotal-cloudops/omen/internal/predeploy"
+	"github.com/pivotal-cloudops/omen/internal/redact"
 	"github.com/pivotal-cloudops/omen/internal/tile"
 )
 
@@ -33,10 +42,17 @@ var (
 var _ = Describe("Apply Changes - Execute", func() {
 	var mockClient *applychangesfakes.FakeOpsmanClient
 	var reportPrinter *applychangesfakes.FakeReportPrinter
+	var installationWatcher *applychangesfakes.FakeInstallationWatcher
+	var preDeployChecker *applychangesfakes.FakePreDeployChecker
+	var configDiffer *applychangesfakes.FakeConfigDiffer
 
 	BeforeEach(func() {
 		mockClient = &applychangesfakes.FakeOpsmanClient{}
+		mockClient.GetReturns([]byte(`{"errands": []}`), nil)
 		reportPrinter = &applychangesfakes.FakeReportPrinter{}
+		installationWatcher = &applychangesfakes.FakeInstallationWatcher{}
+		preDeployChecker = &applychangesfakes.FakePreDeployChecker{}
+		configDiffer = &applychangesfakes.FakeConfigDiffer{}
 	})
 
 	It("Applies all changes by default", func() {
@@ -49,12 +65,12 @@ var _ = Describe("Apply Changes - Execute", func() {
 
 		tilesLoader := fakes.FakeTilesLoader{}
 
-		subject := applychanges.NewApplyChangesOp(manifestsLoader, tilesLoader, mockClient, reportPrinter, applychanges.ApplyChangesOptions{TileSlugs: []string{}, NonInteractive: true})
-		subject.Execute()
+		subject := applychanges.NewApplyChangesOp(manifestsLoader, tilesLoader, mockClient, reportPrinter, installationWatcher, preDeployChecker, configDiffer, applychanges.ApplyChangesOptions{TileSlugs: []string{}, NonInteractive: true})
+		subject.Execute(context.Background())
 
-		postedUrl, postedBody, _ := mockClient.PostArgsForCall(0)
+		_, postedUrl, postedBody, _ := mockClient.PostArgsForCall(0)
 		Expect(postedUrl).To(Equal("/api/v0/installations"))
-		Expect(postedBody).To(MatchJSON(`{"ignore_warnings": true, "deploy_products": "all"}`))
+		Expect(postedBody).To(MatchJSON(`{"ignore_warnings": false, "deploy_products": "all"}`))
 	})
 
 	Describe("no changes between staged and deployed", func() {
@@ -75,19 +91,22 @@ var _ = Describe("Apply Changes - Execute", func() {
 				tilesLoader,
 				mockClient,
 				reportPrinter,
+				installationWatcher,
+				preDeployChecker,
+				configDiffer,
 				applychanges.ApplyChangesOptions{TileSlugs: []string{}, NonInteractive: true})
 		})
 
 		It("applies changes", func() {
-			subject.Execute()
+			subject.Execute(context.Background())
 
-			postedUrl, postedBody, _ := mockClient.PostArgsForCall(0)
+			_, postedUrl, postedBody, _ := mockClient.PostArgsForCall(0)
 			Expect(postedUrl).To(Equal("/api/v0/installations"))
-			Expect(postedBody).To(MatchJSON(`{"ignore_warnings": true, "deploy_products": "all"}`))
+			Expect(postedBody).To(MatchJSON(`{"ignore_warnings": false, "deploy_products": "all"}`))
 		})
 
 		It("produces a warning for a full run", func() {
-			subject.Execute()
+			subject.Execute(context.Background())
 
 			Expect(reportPrinter.Invocations()).To(HaveLen(1))
 			warning := reportPrinter.PrintReportArgsForCall(0)
@@ -119,12 +138,12 @@ var _ = Describe("Apply Changes - Execute", func() {
 			LoadAllStagedStub:   loadAllManifestsStub(stagedManifests, nil),
 		}
 
-		subject := applychanges.NewApplyChangesOp(manifestsLoader, tilesLoader, mockClient, reportPrinter, applychanges.ApplyChangesOptions{TileSlugs: []string{}, NonInteractive: true})
-		subject.Execute()
+		subject := applychanges.NewApplyChangesOp(manifestsLoader, tilesLoader, mockClient, reportPrinter, installationWatcher, preDeployChecker, configDiffer, applychanges.ApplyChangesOptions{TileSlugs: []string{}, NonInteractive: true})
+		subject.Execute(context.Background())
 
-		postedUrl, postedBody, _ := mockClient.PostArgsForCall(0)
+		_, postedUrl, postedBody, _ := mockClient.PostArgsForCall(0)
 		Expect(postedUrl).To(Equal("/api/v0/installations"))
-		Expect(postedBody).To(MatchJSON(`{"deploy_products": "all", "ignore_warnings": true}`))
+		Expect(postedBody).To(MatchJSON(`{"deploy_products": "all", "ignore_warnings": false}`))
 	})
 
 	It("Prints out the the diff between all staged and deployed tiles", func() {
@@ -150,10 +169,124 @@ var _ = Describe("Apply Changes - Execute", func() {
 
 		tilesLoader := fakes.FakeTilesLoader{}
 
-		subject := applychanges.NewApplyChangesOp(manifestsLoader, tilesLoader, mockClient, reportPrinter, applychanges.ApplyChangesOptions{TileSlugs: []string{}, NonInteractive: true})
-		subject.Execute()
+		subject := applychanges.NewApplyChangesOp(manifestsLoader, tilesLoader, mockClient, reportPrinter, installationWatcher, preDeployChecker, configDiffer, applychanges.ApplyChangesOptions{TileSlugs: []string{}, NonInteractive: true})
+		subject.Execute(context.Background())
+		diff := reportPrinter.PrintReportArgsForCall(1)
+		Expect(diff).To(Equal("@@ manifests[deployed] @@\n-manifests[deployed].name=deployed\n@@ manifests[staged] @@\n+manifests[staged].name=staged\n"))
+	})
+
+	It("Prints out a structured diff when asked for JSON", func() {
+		manifestsLoader := &applychangesfakes.FakeManifestsLoader{
+			LoadAllDeployedStub: loadAllManifestsStub(manifest.Manifests{Data: []manifest.Manifest{{Name: "cf", Update: "old"}}}, nil),
+			LoadAllStagedStub:   loadAllManifestsStub(manifest.Manifests{Data: []manifest.Manifest{{Name: "cf", Update: "new"}}}, nil),
+		}
+
+		subject := applychanges.NewApplyChangesOp(manifestsLoader, fakes.FakeTilesLoader{}, mockClient, reportPrinter, installationWatcher, preDeployChecker, configDiffer, applychanges.ApplyChangesOptions{TileSlugs: []string{}, NonInteractive: true, DryRun: true, DiffFormat: applychanges.DiffFormatJSON})
+		Expect(subject.Execute(context.Background())).To(Succeed())
+
 		diff := reportPrinter.PrintReportArgsForCall(0)
-		Expect(diff).To(Equal("-manifests.deployed.name=deployed\n+manifests.staged.name=staged\n"))
+		Expect(diff).To(MatchJSON(`[
+			{"path": "manifests[cf].update", "product": "cf", "kind": "changed", "old_value": "old", "new_value": "new"}
+		]`))
+	})
+
+	It("Renders the diff in the requested style", func() {
+		manifestsLoader := &applychangesfakes.FakeManifestsLoader{
+			LoadAllDeployedStub: loadAllManifestsStub(manifest.Manifests{Data: []manifest.Manifest{{Name: "cf", Update: "old"}}}, nil),
+			LoadAllStagedStub:   loadAllManifestsStub(manifest.Manifests{Data: []manifest.Manifest{{Name: "cf", Update: "new"}}}, nil),
+		}
+
+		subject := applychanges.NewApplyChangesOp(manifestsLoader, fakes.FakeTilesLoader{}, mockClient, reportPrinter, installationWatcher, preDeployChecker, configDiffer, applychanges.ApplyChangesOptions{TileSlugs: []string{}, NonInteractive: true, DryRun: true, DiffStyle: diff.StyleUnified, DiffContext: 1})
+		Expect(subject.Execute(context.Background())).To(Succeed())
+
+		Expect(reportPrinter.PrintReportArgsForCall(1)).To(Equal(
+			"@@ manifests[cf] @@\n manifests[cf].name=cf\n-manifests[cf].update=old\n+manifests[cf].update=new\n"))
+	})
+
+	It("Suppresses ignored paths from the diff", func() {
+		manifestsLoader := &applychangesfakes.FakeManifestsLoader{
+			LoadAllDeployedStub: loadAllManifestsStub(manifest.Manifests{Data: []manifest.Manifest{{Name: "cf", Update: "old"}}, CloudConfig: "old"}, nil),
+			LoadAllStagedStub:   loadAllManifestsStub(manifest.Manifests{Data: []manifest.Manifest{{Name: "cf", Update: "new"}}, CloudConfig: "new"}, nil),
+		}
+
+		subject := applychanges.NewApplyChangesOp(manifestsLoader, fakes.FakeTilesLoader{}, mockClient, reportPrinter, installationWatcher, preDeployChecker, configDiffer, applychanges.ApplyChangesOptions{TileSlugs: []string{}, NonInteractive: true, DryRun: true, IgnoreRules: diff.IgnoreRules{{Pattern: "cloud_config"}}})
+		Expect(subject.Execute(context.Background())).To(Succeed())
+
+		Expect(reportPrinter.PrintReportArgsForCall(1)).To(Equal(
+			"@@ manifests[cf] @@\n-manifests[cf].update=old\n+manifests[cf].update=new\n2 changed lines suppressed by ignore rules\n"))
+	})
+
+	It("Prints a risk summary before the diff", func() {
+		manifestsLoader := &applychangesfakes.FakeManifestsLoader{
+			LoadAllDeployedStub: loadAllManifestsStub(manifest.Manifests{Data: []manifest.Manifest{{Name: "cf", InstanceGroups: []interface{}{map[string]interface{}{"name": "router", "instances": 3}}}}}, nil),
+			LoadAllStagedStub:   loadAllManifestsStub(manifest.Manifests{Data: []manifest.Manifest{{Name: "cf", InstanceGroups: []interface{}{map[string]interface{}{"name": "router", "instances": 2}}}}}, nil),
+		}
+
+		subject := applychanges.NewApplyChangesOp(manifestsLoader, fakes.FakeTilesLoader{}, mockClient, reportPrinter, installationWatcher, preDeployChecker, configDiffer, applychanges.ApplyChangesOptions{TileSlugs: []string{}, NonInteractive: true, DryRun: true})
+		Expect(subject.Execute(context.Background())).To(Succeed())
+
+		summary := reportPrinter.PrintReportArgsForCall(0)
+		Expect(summary).To(ContainSubstring("cf       HIGH  scale down (router 3 -> 2)"))
+		Expect(summary).To(ContainSubstring("WARNING: scale down in cf: router 3 -> 2"))
+		Expect(reportPrinter.PrintReportArgsForCall(1)).To(HavePrefix("@@ manifests[cf].instance_groups[router] @@"))
+	})
+
+	It("Prints the tile configuration diff when asked for", func() {
+		manifestsLoader := &applychangesfakes.FakeManifestsLoader{
+			LoadAllDeployedStub: loadAllManifestsStub(manifest.Manifests{}, nil),
+			LoadAllStagedStub:   loadAllManifestsStub(manifest.Manifests{}, nil),
+		}
+		configDiffer.DiffReturns("@@ products[cf] @@\n-products[cf].version=2.1.0\n+products[cf].version=2.2.0\n", nil)
+
+		subject := applychanges.NewApplyChangesOp(manifestsLoader, fakes.FakeTilesLoader{}, mockClient, reportPrinter, installationWatcher, preDeployChecker, configDiffer, applychanges.ApplyChangesOptions{TileSlugs: []string{}, NonInteractive: true, DryRun: true, IncludeConfigDiff: true})
+		Expect(subject.Execute(context.Background())).To(Succeed())
+
+		Expect(configDiffer.DiffCallCount()).To(Equal(1))
+		_, diffGuids := configDiffer.DiffArgsForCall(0)
+		Expect(diffGuids).To(BeEmpty())
+		lastReport := reportPrinter.PrintReportArgsForCall(reportPrinter.PrintReportCallCount() - 1)
+		Expect(lastReport).To(Equal("Tile configuration changes:\n@@ products[cf] @@\n-products[cf].version=2.1.0\n+products[cf].version=2.2.0\n"))
+	})
+
+	It("Does not diff the tile configuration by default", func() {
+		manifestsLoader := &applychangesfakes.FakeManifestsLoader{
+			LoadAllDeployedStub: loadAllManifestsStub(manifest.Manifests{}, nil),
+			LoadAllStagedStub:   loadAllManifestsStub(manifest.Manifests{}, nil),
+		}
+
+		subject := applychanges.NewApplyChangesOp(manifestsLoader, fakes.FakeTilesLoader{}, mockClient, reportPrinter, installationWatcher, preDeployChecker, configDiffer, applychanges.ApplyChangesOptions{TileSlugs: []string{}, NonInteractive: true, DryRun: true})
+		Expect(subject.Execute(context.Background())).To(Succeed())
+
+		Expect(configDiffer.DiffCallCount()).To(BeZero())
+	})
+
+	Describe("secrets in the diff", func() {
+		var manifestsLoader *applychangesfakes.FakeManifestsLoader
+
+		BeforeEach(func() {
+			manifestsLoader = &applychangesfakes.FakeManifestsLoader{
+				LoadAllDeployedStub: loadAllManifestsStub(manifest.Manifests{Data: []manifest.Manifest{{Name: "cf", Update: map[string]interface{}{"password": "old"}}}}, nil),
+				LoadAllStagedStub:   loadAllManifestsStub(manifest.Manifests{Data: []manifest.Manifest{{Name: "cf", Update: map[string]interface{}{"password": "new"}}}}, nil),
+			}
+		})
+
+		It("are redacted by default", func() {
+			subject := applychanges.NewApplyChangesOp(manifestsLoader, fakes.FakeTilesLoader{}, mockClient, reportPrinter, installationWatcher, preDeployChecker, configDiffer, applychanges.ApplyChangesOptions{TileSlugs: []string{}, NonInteractive: true, DryRun: true})
+			Expect(subject.Execute(context.Background())).To(Succeed())
+
+			Expect(reportPrinter.PrintReportArgsForCall(1)).To(Equal(
+				"@@ manifests[cf] @@\n" +
+					"-manifests[cf].update.password=" + redact.Value("old") + "\n" +
+					"+manifests[cf].update.password=" + redact.Value("new") + "\n"))
+		})
+
+		It("are shown when asked for", func() {
+			subject := applychanges.NewApplyChangesOp(manifestsLoader, fakes.FakeTilesLoader{}, mockClient, reportPrinter, installationWatcher, preDeployChecker, configDiffer, applychanges.ApplyChangesOptions{TileSlugs: []string{}, NonInteractive: true, DryRun: true, ShowSecrets: true})
+			Expect(subject.Execute(context.Background())).To(Succeed())
+
+			Expect(reportPrinter.PrintReportArgsForCall(1)).To(Equal(
+				"@@ manifests[cf] @@\n-manifests[cf].update.password=old\n+manifests[cf].update.password=new\n"))
+		})
 	})
 
 	Describe("selective tile deployments", func() {
@@ -173,14 +306,14 @@ var _ = Describe("Apply Changes - Execute", func() {
 				},
 			}
 
-			subject := applychanges.NewApplyChangesOp(manifestsLoader, tilesLoader, mockClient, reportPrinter, applychanges.ApplyChangesOptions{TileSlugs: []string{"product1", "product2"}, NonInteractive: true})
-			subject.Execute()
+			subject := applychanges.NewApplyChangesOp(manifestsLoader, tilesLoader, mockClient, reportPrinter, installationWatcher, preDeployChecker, configDiffer, applychanges.ApplyChangesOptions{TileSlugs: []string{"product1", "product2"}, NonInteractive: true})
+			subject.Execute(context.Background())
 
 			Expect(fetchTileMetadata).To(BeFalse())
 
-			postedUrl, postedBody, _ := mockClient.PostArgsForCall(0)
+			_, postedUrl, postedBody, _ := mockClient.PostArgsForCall(0)
 			Expect(postedUrl).To(Equal("/api/v0/installations"))
-			Expect(postedBody).To(MatchJSON(`{"ignore_warnings": true, "deploy_products": ["guid1","guid2"]}`))
+			Expect(postedBody).To(MatchJSON(`{"ignore_warnings": false, "deploy_products": ["guid1","guid2"]}`))
 		})
 
 		It("fails when slug not found", func() {
@@ -195,8 +328,8 @@ var _ = Describe("Apply Changes - Execute", func() {
 				},
 			}
 
-			subject := applychanges.NewApplyChangesOp(manifestsLoader, tilesLoader, mockClient, reportPrinter, applychanges.ApplyChangesOptions{TileSlugs: []string{"product3", "product2"}, NonInteractive: true})
-			err := subject.Execute()
+			subject := applychanges.NewApplyChangesOp(manifestsLoader, tilesLoader, mockClient, reportPrinter, installationWatcher, preDeployChecker, configDiffer, applychanges.ApplyChangesOptions{TileSlugs: []string{"product3", "product2"}, NonInteractive: true})
+			err := subject.Execute(context.Background())
 
 			Expect(err).To(HaveOccurred())
 
@@ -215,8 +348,8 @@ var _ = Describe("Apply Changes - Execute", func() {
 				},
 			}
 
-			subject := applychanges.NewApplyChangesOp(manifestsLoader, tilesLoader, mockClient, reportPrinter, applychanges.ApplyChangesOptions{TileSlugs: []string{"product3"}, NonInteractive: true})
-			err := subject.Execute()
+			subject := applychanges.NewApplyChangesOp(manifestsLoader, tilesLoader, mockClient, reportPrinter, installationWatcher, preDeployChecker, configDiffer, applychanges.ApplyChangesOptions{TileSlugs: []string{"product3"}, NonInteractive: true})
+			err := subject.Execute(context.Background())
 
 			Expect(err).To(HaveOccurred())
 			Expect(err.Error()).To(Equal("can't load tiles"))
@@ -230,7 +363,7 @@ var _ = Describe("Apply Changes - Execute", func() {
 				LoadAllDeployedStub: loadAllManifestsStub(manifest.Manifests{}, errors.New("loadAll should not be called")),
 				LoadAllStagedStub:   loadAllManifestsStub(manifest.Manifests{}, errors.New("loadAll should not be called")),
 
-				LoadDeployedStub: func(tileGuids []string) (manifest.Manifests, error) {
+				LoadDeployedStub: func(_ context.Context, tileGuids []string) (manifest.Manifests, error) {
 					if reflect.DeepEqual(tileGuids, []string{"guid1", "guid2"}) {
 						return manifest.Manifests{
 							Data: []manifest.Manifest{
@@ -245,7 +378,7 @@ var _ = Describe("Apply Changes - Execute", func() {
 					}
 					return manifest.Manifests{}, errors.New("don't know how to load these manifests")
 				},
-				LoadStagedStub: func(tileGuids []string) (manifest.Manifests, error) {
+				LoadStagedStub: func(_ context.Context, tileGuids []string) (manifest.Manifests, error) {
 
 					if reflect.DeepEqual(tileGuids, []string{"guid1", "guid2"}) {
 						return manifest.Manifests{
@@ -269,9 +402,9 @@ var _ = Describe("Apply Changes - Execute", func() {
 				},
 			}
 
-			subject := applychanges.NewApplyChangesOp(manifestsLoader, tilesLoader, mockClient, reportPrinter, applychanges.ApplyChangesOptions{TileSlugs: []string{"product1", "product2"}, NonInteractive: true})
-			subject.Execute()
-			diff := reportPrinter.PrintReportArgsForCall(0)
+			subject := applychanges.NewApplyChangesOp(manifestsLoader, tilesLoader, mockClient, reportPrinter, installationWatcher, preDeployChecker, configDiffer, applychanges.ApplyChangesOptions{TileSlugs: []string{"product1", "product2"}, NonInteractive: true})
+			subject.Execute(context.Background())
+			diff := reportPrinter.PrintReportArgsForCall(1)
 
 			expectedDiff, err := ioutil.ReadFile("testdata/diff.txt")
 			Expect(err).ToNot(HaveOccurred())
@@ -305,10 +438,10 @@ var _ = Describe("Apply Changes - Execute", func() {
 
 			tilesLoader := fakes.FakeTilesLoader{}
 
-			subject := applychanges.NewApplyChangesOp(manifestsLoader, tilesLoader, mockClient, reportPrinter, applychanges.ApplyChangesOptions{[]string{}, true, true, false})
-			subject.Execute()
-			diff := reportPrinter.PrintReportArgsForCall(0)
-			Expect(diff).To(Equal("-manifests.deployed.name=deployed\n+manifests.staged.name=staged\n"))
+			subject := applychanges.NewApplyChangesOp(manifestsLoader, tilesLoader, mockClient, reportPrinter, installationWatcher, preDeployChecker, configDiffer, applychanges.ApplyChangesOptions{TileSlugs: []string{}, NonInteractive: true, DryRun: true})
+			subject.Execute(context.Background())
+			diff := reportPrinter.PrintReportArgsForCall(1)
+			Expect(diff).To(Equal("@@ manifests[deployed] @@\n-manifests[deployed].name=deployed\n@@ manifests[staged] @@\n+manifests[staged].name=staged\n"))
 
 			Expect(mockClient.PostCallCount()).To(BeZero())
 		})
@@ -323,24 +456,553 @@ var _ = Describe("Apply Changes - Execute", func() {
 
 			mockClient.PostReturns([]byte(applyChangesReply), nil)
 
-			subject := applychanges.NewApplyChangesOp(manifestsLoader, tilesLoader, mockClient, reportPrinter, applychanges.ApplyChangesOptions{[]string{}, true, false, true})
-			subject.Execute()
+			subject := applychanges.NewApplyChangesOp(manifestsLoader, tilesLoader, mockClient, reportPrinter, installationWatcher, preDeployChecker, configDiffer, applychanges.ApplyChangesOptions{TileSlugs: []string{}, NonInteractive: true, Quiet: true})
+			subject.Execute(context.Background())
 			Expect(reportPrinter.PrintReportCallCount()).To(Equal(1))
 
 			Expect(reportPrinter.PrintReportArgsForCall(0)).To(MatchJSON(applyChangesReply))
 		})
 	})
 
+	Describe("Pre-flight validation", func() {
+		var (
+			tilesLoader     fakes.FakeTilesLoader
+			manifestsLoader *applychangesfakes.FakeManifestsLoader
+		)
+
+		BeforeEach(func() {
+			tilesLoader = fakes.FakeTilesLoader{
+				StagedResponseFunc: func(b bool) (tile.Tiles, error) {
+					return twoTiles, nil
+				},
+			}
+			manifestsLoader = &applychangesfakes.FakeManifestsLoader{
+				LoadDeployedStub: loadManifestsStub(manifest.Manifests{}, nil),
+				LoadStagedStub:   loadManifestsStub(manifest.Manifests{}, nil),
+			}
+		})
+
+		It("validates the selected products", func() {
+			subject := applychanges.NewApplyChangesOp(manifestsLoader, tilesLoader, mockClient, reportPrinter, installationWatcher, preDeployChecker, configDiffer, applychanges.ApplyChangesOptions{TileSlugs: []string{"product1"}, NonInteractive: true})
+			Expect(subject.Execute(context.Background())).To(Succeed())
+
+			_, checkedGuids := preDeployChecker.CheckArgsForCall(0)
+			Expect(checkedGuids).To(Equal([]string{"guid1"}))
+		})
+
+		It("does not apply changes when there are blocking errors", func() {
+			preDeployChecker.CheckReturns(predeploy.Report{Products: []predeploy.ProductReport{
+				{GUID: "guid1", Identifier: "product1", Errors: []string{"configuration is incomplete"}},
+			}}, nil)
+
+			subject := applychanges.NewApplyChangesOp(manifestsLoader, tilesLoader, mockClient, reportPrinter, installationWatcher, preDeployChecker, configDiffer, applychanges.ApplyChangesOptions{TileSlugs: []string{"product1"}, NonInteractive: true})
+			err := subject.Execute(context.Background())
+
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("configuration is incomplete"))
+			Expect(mockClient.PostCallCount()).To(BeZero())
+		})
+
+		It("does not apply changes when warnings are not ignored", func() {
+			preDeployChecker.CheckReturns(predeploy.Report{Products: []predeploy.ProductReport{
+				{GUID: "guid1", Identifier: "product1", Warnings: []string{"WildcardDomainVerifier: no dns"}},
+			}}, nil)
+
+			subject := applychanges.NewApplyChangesOp(manifestsLoader, tilesLoader, mockClient, reportPrinter, installationWatcher, preDeployChecker, configDiffer, applychanges.ApplyChangesOptions{TileSlugs: []string{"product1"}, NonInteractive: true, Quiet: true})
+			err := subject.Execute(context.Background())
+
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("WildcardDomainVerifier: no dns"))
+			Expect(err.Error()).To(ContainSubstring("--ignore-warnings"))
+			Expect(mockClient.PostCallCount()).To(BeZero())
+		})
+
+		It("prints warnings and only ignores them when asked to", func() {
+			preDeployChecker.CheckReturns(predeploy.Report{Products: []predeploy.ProductReport{
+				{GUID: "guid1", Identifier: "product1", Warnings: []string{"WildcardDomainVerifier: no dns"}},
+			}}, nil)
+
+			subject := applychanges.NewApplyChangesOp(manifestsLoader, tilesLoader, mockClient, reportPrinter, installationWatcher, preDeployChecker, configDiffer, applychanges.ApplyChangesOptions{TileSlugs: []string{"product1"}, NonInteractive: true, IgnoreWarnings: true})
+			Expect(subject.Execute(context.Background())).To(Succeed())
+
+			Expect(reportPrinter.PrintReportArgsForCall(1)).To(ContainSubstring("WildcardDomainVerifier: no dns"))
+			_, _, postedBody, _ := mockClient.PostArgsForCall(0)
+			Expect(postedBody).To(MatchJSON(`{"ignore_warnings": true, "deploy_products": ["guid1"]}`))
+		})
+	})
+
+	Describe("Errand overrides", func() {
+		var (
+			tilesLoader     fakes.FakeTilesLoader
+			manifestsLoader *applychangesfakes.FakeManifestsLoader
+		)
+
+		BeforeEach(func() {
+			tilesLoader = fakes.FakeTilesLoader{
+				StagedResponseFunc: func(b bool) (tile.Tiles, error) {
+					return twoTiles, nil
+				},
+			}
+			manifestsLoader = &applychangesfakes.FakeManifestsLoader{
+				LoadAllDeployedStub: loadAllManifestsStub(manifest.Manifests{}, nil),
+				LoadAllStagedStub:   loadAllManifestsStub(manifest.Manifests{}, nil),
+				LoadDeployedStub:    loadManifestsStub(manifest.Manifests{}, nil),
+				LoadStagedStub:      loadManifestsStub(manifest.Manifests{}, nil),
+			}
+		})
+
+		It("sends the errands to skip and run for this installation only", func() {
+			overrides := []applychanges.ErrandOverride{
+				{ProductSlug: "product1", Errand: "smoke-tests", Run: false},
+				{ProductSlug: "product2", Errand: "push-apps", Run: true},
+			}
+
+			subject := applychanges.NewApplyChangesOp(manifestsLoader, tilesLoader, mockClient, reportPrinter, installationWatcher, preDeployChecker, configDiffer, applychanges.ApplyChangesOptions{NonInteractive: true, ErrandOverrides: overrides})
+			Expect(subject.Execute(context.Background())).To(Succeed())
+
+			_, _, postedBody, _ := mockClient.PostArgsForCall(0)
+			Expect(postedBody).To(MatchJSON(`{
+				"ignore_warnings": false,
+				"deploy_products": "all",
+				"errands": {
+					"guid1": {"run_post_deploy": {"smoke-tests": false}},
+					"guid2": {"run_post_deploy": {"push-apps": true}}
+				}
+			}`))
+
+			Expect(reportPrinter.PrintReportArgsForCall(1)).To(Equal("Post-deploy errands for this run:\n" +
+				"product1\tsmoke-tests\tskip (override)\n" +
+				"product2\tpush-apps\trun (override)\n"))
+		})
+
+		It("shows the staged post-deploy errands merged with the overrides", func() {
+			mockClient.GetStub = func(ctx context.Context, endpoint string, timeout time.Duration) ([]byte, error) {
+				if endpoint == "/api/v0/staged/products/guid1/errands" {
+					return []byte(`{"errands": [
+						{"name": "smoke-tests", "post_deploy": true},
+						{"name": "push-apps-manager", "post_deploy": "when-changed"},
+						{"name": "delete-all-apps", "pre_delete": true}
+					]}`), nil
+				}
+				return []byte(`{"errands": [
+					{"name": "smoke-tests", "post_deploy": true},
+					{"name": "upgrade-all", "post_deploy": false}
+				]}`), nil
+			}
+			overrides := []applychanges.ErrandOverride{{ProductSlug: "product1", Errand: "smoke-tests", Run: false}}
+
+			subject := applychanges.NewApplyChangesOp(manifestsLoader, tilesLoader, mockClient, reportPrinter, installationWatcher, preDeployChecker, configDiffer, applychanges.ApplyChangesOptions{NonInteractive: true, ErrandOverrides: overrides})
+			Expect(subject.Execute(context.Background())).To(Succeed())
+
+			Expect(reportPrinter.PrintReportArgsForCall(1)).To(Equal("Post-deploy errands for this run:\n" +
+				"product1\tsmoke-tests\tskip (override)\n" +
+				"product1\tpush-apps-manager\trun when changed\n" +
+				"product2\tsmoke-tests\trun\n" +
+				"product2\tupgrade-all\tskip\n"))
+		})
+
+		It("shows the post-deploy errands of the products being deployed without overrides", func() {
+			mockClient.GetReturns([]byte(`{"errands": [{"name": "smoke-tests", "post_deploy": true}]}`), nil)
+
+			subject := applychanges.NewApplyChangesOp(manifestsLoader, tilesLoader, mockClient, reportPrinter, installationWatcher, preDeployChecker, configDiffer, applychanges.ApplyChangesOptions{TileSlugs: []string{"product2"}, NonInteractive: true})
+			Expect(subject.Execute(context.Background())).To(Succeed())
+
+			Expect(mockClient.GetCallCount()).To(Equal(1))
+			_, endpoint, _ := mockClient.GetArgsForCall(0)
+			Expect(endpoint).To(Equal("/api/v0/staged/products/guid2/errands"))
+			Expect(reportPrinter.PrintReportArgsForCall(1)).To(Equal("Post-deploy errands for this run:\n" +
+				"product2\tsmoke-tests\trun\n"))
+		})
+
+		It("fails before applying when the product is not being deployed", func() {
+			overrides := []applychanges.ErrandOverride{{ProductSlug: "product2", Errand: "smoke-tests"}}
+
+			subject := applychanges.NewApplyChangesOp(manifestsLoader, tilesLoader, mockClient, reportPrinter, installationWatcher, preDeployChecker, configDiffer, applychanges.ApplyChangesOptions{TileSlugs: []string{"product1"}, NonInteractive: true, ErrandOverrides: overrides})
+			err := subject.Execute(context.Background())
+
+			Expect(err).To(MatchError("errand override for smoke-tests, but product2 is not being deployed"))
+			Expect(mockClient.PostCallCount()).To(BeZero())
+		})
+
+		It("fails when the same errand is skipped and run", func() {
+			overrides := []applychanges.ErrandOverride{
+				{ProductSlug: "product1", Errand: "smoke-tests", Run: false},
+				{ProductSlug: "product1", Errand: "smoke-tests", Run: true},
+			}
+
+			subject := applychanges.NewApplyChangesOp(manifestsLoader, tilesLoader, mockClient, reportPrinter, installationWatcher, preDeployChecker, configDiffer, applychanges.ApplyChangesOptions{NonInteractive: true, ErrandOverrides: overrides})
+			Expect(subject.Execute(context.Background())).To(MatchError("errand smoke-tests of product1 is both skipped and run"))
+		})
+	})
+
+	Describe("ParseErrandOverrides", func() {
+		It("parses product and errand pairs", func() {
+			overrides, err := applychanges.ParseErrandOverrides([]string{"p-redis:smoke-tests", " cf:push-apps-manager"}, true)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(overrides).To(Equal([]applychanges.ErrandOverride{
+				{ProductSlug: "p-redis", Errand: "smoke-tests", Run: true},
+				{ProductSlug: "cf", Errand: "push-apps-manager", Run: true},
+			}))
+		})
+
+		It("rejects values without an errand", func() {
+			_, err := applychanges.ParseErrandOverrides([]string{"p-redis"}, false)
+			Expect(err).To(MatchError(`invalid errand override "p-redis", expected <product-slug>:<errand-name>`))
+		})
+	})
+
+	Describe("Plan", func() {
+		var (
+			tilesLoader       fakes.FakeTilesLoader
+			manifestsLoader   *applychangesfakes.FakeManifestsLoader
+			stagedManifests   manifest.Manifests
+			deployedManifests manifest.Manifests
+			stagedConfig      func() tile.Tiles
+			tmpdir            string
+		)
+
+		tilesWithTimeout := func(timeout int) tile.Tiles {
+			return tile.Tiles{Data: []*tile.Tile{
+				{GUID: "guid1", Type: "product1"},
+				{GUID: "guid2", Type: "product2", Properties: map[string]interface{}{"properties": map[string]interface{}{
+					".properties.timeout": map[string]interface{}{"value": timeout},
+				}}},
+			}}
+		}
+
+		BeforeEach(func() {
+			var err error
+			tmpdir, err = ioutil.TempDir("", "")
+			Expect(err).NotTo(HaveOccurred())
+
+			stagedManifests = manifest.Manifests{Data: []manifest.Manifest{{Name: "staged"}}}
+			deployedManifests = manifest.Manifests{Data: []manifest.Manifest{{Name: "deployed"}}}
+			stagedConfig = func() tile.Tiles { return tilesWithTimeout(30) }
+
+			tilesLoader = fakes.FakeTilesLoader{
+				StagedResponseFunc: func(fetchTileMetadata bool) (tile.Tiles, error) {
+					if fetchTileMetadata {
+						return stagedConfig(), nil
+					}
+					return twoTiles, nil
+				},
+			}
+			manifestsLoader = &applychangesfakes.FakeManifestsLoader{
+				LoadDeployedStub: loadManifestsStub(deployedManifests, nil),
+				LoadStagedStub: func(context.Context, []string) (manifest.Manifests, error) {
+					return stagedManifests, nil
+				},
+			}
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(tmpdir)
+		})
+
+		writePlan := func() string {
+			planFile := filepath.Join(tmpdir, "plan.json")
+			subject := applychanges.NewApplyChangesOp(manifestsLoader, tilesLoader, mockClient, reportPrinter, installationWatcher, preDeployChecker, configDiffer,
+				applychanges.ApplyChangesOptions{TileSlugs: []string{"product2"}, NonInteractive: true, DryRun: true, PlanOutput: planFile})
+			Expect(subject.Execute(context.Background())).To(Succeed())
+			return planFile
+		}
+
+		It("writes the selected products and staged fingerprint on a dry run", func() {
+			planFile := writePlan()
+
+			plan, err := applychanges.ReadPlan(planFile)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(plan.TileGuids).To(Equal([]string{"guid2"}))
+			Expect(plan.StagedFingerprint).NotTo(BeEmpty())
+			Expect(mockClient.PostCallCount()).To(BeZero())
+		})
+
+		It("applies exactly the planned products when nothing has changed", func() {
+			planFile := writePlan()
+
+			subject := applychanges.NewApplyChangesOp(manifestsLoader, tilesLoader, mockClient, reportPrinter, installationWatcher, preDeployChecker, configDiffer,
+				applychanges.ApplyChangesOptions{NonInteractive: true, PlanFile: planFile})
+			Expect(subject.Execute(context.Background())).To(Succeed())
+
+			_, stagedGuids := manifestsLoader.LoadStagedArgsForCall(1)
+			Expect(stagedGuids).To(Equal([]string{"guid2"}))
+			_, _, postedBody, _ := mockClient.PostArgsForCall(0)
+			Expect(postedBody).To(MatchJSON(`{"ignore_warnings": false, "deploy_products": ["guid2"]}`))
+		})
+
+		It("refuses to apply when the staged manifests have drifted", func() {
+			planFile := writePlan()
+			stagedManifests = manifest.Manifests{Data: []manifest.Manifest{{Name: "staged-again"}}}
+
+			subject := applychanges.NewApplyChangesOp(manifestsLoader, tilesLoader, mockClient, reportPrinter, installationWatcher, preDeployChecker, configDiffer,
+				applychanges.ApplyChangesOptions{NonInteractive: true, PlanFile: planFile})
+			err := subject.Execute(context.Background())
+
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("staged changes have drifted since the plan was made"))
+			Expect(mockClient.PostCallCount()).To(BeZero())
+		})
+
+		It("refuses to apply when the staged configuration has drifted", func() {
+			planFile := writePlan()
+			stagedConfig = func() tile.Tiles { return tilesWithTimeout(60) }
+
+			subject := applychanges.NewApplyChangesOp(manifestsLoader, tilesLoader, mockClient, reportPrinter, installationWatcher, preDeployChecker, configDiffer,
+				applychanges.ApplyChangesOptions{NonInteractive: true, PlanFile: planFile})
+			err := subject.Execute(context.Background())
+
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("staged changes have drifted since the plan was made"))
+			Expect(mockClient.PostCallCount()).To(BeZero())
+		})
+
+		It("ignores changes to products outside the plan", func() {
+			planFile := writePlan()
+			stagedConfig = func() tile.Tiles {
+				tiles := tilesWithTimeout(30)
+				tiles.Data[0].Properties = map[string]interface{}{"properties": map[string]interface{}{}}
+				return tiles
+			}
+
+			subject := applychanges.NewApplyChangesOp(manifestsLoader, tilesLoader, mockClient, reportPrinter, installationWatcher, preDeployChecker, configDiffer,
+				applychanges.ApplyChangesOptions{NonInteractive: true, PlanFile: planFile})
+			Expect(subject.Execute(context.Background())).To(Succeed())
+			Expect(mockClient.PostCallCount()).To(Equal(1))
+		})
+
+		It("verifies the plan again right before applying", func() {
+			planFile := writePlan()
+			loads := 0
+			stagedConfig = func() tile.Tiles {
+				loads++
+				if loads > 1 {
+					return tilesWithTimeout(60)
+				}
+				return tilesWithTimeout(30)
+			}
+
+			subject := applychanges.NewApplyChangesOp(manifestsLoader, tilesLoader, mockClient, reportPrinter, installationWatcher, preDeployChecker, configDiffer,
+				applychanges.ApplyChangesOptions{NonInteractive: true, PlanFile: planFile})
+			err := subject.Execute(context.Background())
+
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("staged changes have drifted since the plan was made"))
+			Expect(loads).To(Equal(2))
+			Expect(mockClient.PostCallCount()).To(BeZero())
+		})
+
+		It("fails when the plan file cannot be read", func() {
+			subject := applychanges.NewApplyChangesOp(manifestsLoader, tilesLoader, mockClient, reportPrinter, installationWatcher, preDeployChecker, configDiffer,
+				applychanges.ApplyChangesOptions{NonInteractive: true, PlanFile: filepath.Join(tmpdir, "missing.json")})
+
+			Expect(subject.Execute(context.Background())).NotTo(Succeed())
+			Expect(mockClient.PostCallCount()).To(BeZero())
+		})
+	})
+
+	Describe("JSON output", func() {
+		var (
+			tilesLoader     fakes.FakeTilesLoader
+			manifestsLoader *applychangesfakes.FakeManifestsLoader
+		)
+
+		BeforeEach(func() {
+			tilesLoader = fakes.FakeTilesLoader{
+				StagedResponseFunc: func(b bool) (tile.Tiles, error) {
+					return twoTiles, nil
+				},
+			}
+			manifestsLoader = &applychangesfakes.FakeManifestsLoader{
+				LoadAllDeployedStub: loadAllManifestsStub(manifest.Manifests{Data: []manifest.Manifest{{Name: "deployed"}}}, nil),
+				LoadAllStagedStub:   loadAllManifestsStub(manifest.Manifests{Data: []manifest.Manifest{{Name: "staged"}}}, nil),
+			}
+			mockClient.PostReturns([]byte(`{"install":{"id": 303}}`), nil)
+		})
+
+		parseResult := func() applychanges.Result {
+			Expect(reportPrinter.PrintReportCallCount()).To(Equal(1))
+			var result applychanges.Result
+			Expect(json.Unmarshal([]byte(reportPrinter.PrintReportArgsForCall(0)), &result)).To(Succeed())
+			return result
+		}
+
+		It("prints only a result document for a dry run", func() {
+			subject := applychanges.NewApplyChangesOp(manifestsLoader, tilesLoader, mockClient, reportPrinter, installationWatcher, preDeployChecker, configDiffer, applychanges.ApplyChangesOptions{NonInteractive: true, DryRun: true, OutputFormat: applychanges.OutputJSON})
+			Expect(subject.Execute(context.Background())).To(Succeed())
+
+			result := parseResult()
+			Expect(result.Status).To(Equal("dry-run"))
+			Expect(result.Products).To(Equal([]string{"guid1", "guid2"}))
+			Expect(result.Diff).To(Equal(applychanges.DiffSummary{Additions: 1, Removals: 1}))
+			Expect(result.InstallationID).To(BeZero())
+			Expect(result.StartedAt).To(BeNil())
+		})
+
+		It("reports the installation that was triggered", func() {
+			subject := applychanges.NewApplyChangesOp(manifestsLoader, tilesLoader, mockClient, reportPrinter, installationWatcher, preDeployChecker, configDiffer, applychanges.ApplyChangesOptions{NonInteractive: true, OutputFormat: applychanges.OutputJSON})
+			Expect(subject.Execute(context.Background())).To(Succeed())
+
+			result := parseResult()
+			Expect(result.Status).To(Equal("triggered"))
+			Expect(result.InstallationID).To(Equal(303))
+			Expect(result.StartedAt).NotTo(BeNil())
+			Expect(result.FinishedAt).To(BeNil())
+		})
+
+		It("reports the final status when waiting", func() {
+			installationWatcher.WaitReturns(installations.Installation{ID: 303, Status: installations.StatusFailed}, errors.New("installation 303 failed"))
+
+			subject := applychanges.NewApplyChangesOp(manifestsLoader, tilesLoader, mockClient, reportPrinter, installationWatcher, preDeployChecker, configDiffer, applychanges.ApplyChangesOptions{NonInteractive: true, Wait: true, OutputFormat: applychanges.OutputJSON})
+			Expect(subject.Execute(context.Background())).To(MatchError("installation 303 failed"))
+
+			result := parseResult()
+			Expect(result.Status).To(Equal("failed"))
+			Expect(result.InstallationID).To(Equal(303))
+			Expect(result.FinishedAt).NotTo(BeNil())
+			Expect(result.Error).To(Equal("installation 303 failed"))
+		})
+
+		It("reports verifier warnings", func() {
+			preDeployChecker.CheckReturns(predeploy.Report{Products: []predeploy.ProductReport{
+				{GUID: "guid1", Identifier: "product1", Warnings: []string{"WildcardDomainVerifier: no dns"}},
+			}}, nil)
+
+			subject := applychanges.NewApplyChangesOp(manifestsLoader, tilesLoader, mockClient, reportPrinter, installationWatcher, preDeployChecker, configDiffer, applychanges.ApplyChangesOptions{NonInteractive: true, OutputFormat: applychanges.OutputJSON})
+			Expect(subject.Execute(context.Background())).NotTo(Succeed())
+
+			result := parseResult()
+			Expect(result.Status).To(Equal("failed"))
+			Expect(result.Warnings).To(Equal([]string{"product1: WildcardDomainVerifier: no dns"}))
+			Expect(mockClient.PostCallCount()).To(BeZero())
+		})
+
+		It("prints a failed result document when it fails before applying", func() {
+			preDeployChecker.CheckReturns(predeploy.Report{}, errors.New("pre-deploy check unavailable"))
+
+			subject := applychanges.NewApplyChangesOp(manifestsLoader, tilesLoader, mockClient, reportPrinter, installationWatcher, preDeployChecker, configDiffer, applychanges.ApplyChangesOptions{NonInteractive: true, OutputFormat: applychanges.OutputJSON})
+			Expect(subject.Execute(context.Background())).To(MatchError("pre-deploy check unavailable"))
+
+			result := parseResult()
+			Expect(result.Status).To(Equal("failed"))
+			Expect(result.Error).To(Equal("pre-deploy check unavailable"))
+			Expect(result.Products).To(Equal([]string{"guid1", "guid2"}))
+			Expect(mockClient.PostCallCount()).To(BeZero())
+		})
+	})
+
+	Describe("Wait", func() {
+		var (
+			tilesLoader     fakes.FakeTilesLoader
+			manifestsLoader *applychangesfakes.FakeManifestsLoader
+		)
+
+		BeforeEach(func() {
+			tilesLoader = fakes.FakeTilesLoader{}
+			manifestsLoader = &applychangesfakes.FakeManifestsLoader{
+				LoadAllDeployedStub: loadAllManifestsStub(manifest.Manifests{}, nil),
+				LoadAllStagedStub:   loadAllManifestsStub(manifest.Manifests{}, nil),
+			}
+			mockClient.PostReturns([]byte(`{"install":{"id": 303}}`), nil)
+		})
+
+		It("does not wait for the installation by default", func() {
+			subject := applychanges.NewApplyChangesOp(manifestsLoader, tilesLoader, mockClient, reportPrinter, installationWatcher, preDeployChecker, configDiffer, applychanges.ApplyChangesOptions{TileSlugs: []string{}, NonInteractive: true})
+			err := subject.Execute(context.Background())
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(installationWatcher.WaitCallCount()).To(BeZero())
+		})
+
+		It("waits for the installation it triggered", func() {
+			installationWatcher.WaitReturns(installations.Installation{ID: 303, Status: installations.StatusSucceeded}, nil)
+
+			subject := applychanges.NewApplyChangesOp(manifestsLoader, tilesLoader, mockClient, reportPrinter, installationWatcher, preDeployChecker, configDiffer, applychanges.ApplyChangesOptions{TileSlugs: []string{}, NonInteractive: true, Wait: true})
+			err := subject.Execute(context.Background())
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(installationWatcher.WaitCallCount()).To(Equal(1))
+			_, id := installationWatcher.WaitArgsForCall(0)
+			Expect(id).To(Equal(303))
+
+			lastReport := reportPrinter.PrintReportArgsForCall(reportPrinter.PrintReportCallCount() - 1)
+			Expect(lastReport).To(Equal("Installation 303 succeeded"))
+		})
+
+		It("fails when the installation fails", func() {
+			installationWatcher.WaitReturns(installations.Installation{ID: 303, Status: installations.StatusFailed}, errors.New("installation 303 failed"))
+
+			subject := applychanges.NewApplyChangesOp(manifestsLoader, tilesLoader, mockClient, reportPrinter, installationWatcher, preDeployChecker, configDiffer, applychanges.ApplyChangesOptions{TileSlugs: []string{}, NonInteractive: true, Wait: true})
+			err := subject.Execute(context.Background())
+
+			Expect(err).To(MatchError("installation 303 failed"))
+		})
+
+		It("says the installation keeps running when waiting for it is interrupted", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			installationWatcher.WaitStub = func(ctx context.Context, id int) (installations.Installation, error) {
+				cancel()
+				return installations.Installation{ID: id, Status: installations.StatusRunning}, ctx.Err()
+			}
+
+			subject := applychanges.NewApplyChangesOp(manifestsLoader, tilesLoader, mockClient, reportPrinter, installationWatcher, preDeployChecker, configDiffer, applychanges.ApplyChangesOptions{TileSlugs: []string{}, NonInteractive: true, Wait: true})
+			err := subject.Execute(ctx)
+
+			Expect(err).To(MatchError("stopped waiting for installation 303, which keeps running in Ops Manager: context canceled"))
+		})
+	})
+
+	Describe("Interrupts", func() {
+		var (
+			tilesLoader     fakes.FakeTilesLoader
+			manifestsLoader *applychangesfakes.FakeManifestsLoader
+			options         applychanges.ApplyChangesOptions
+		)
+
+		BeforeEach(func() {
+			tilesLoader = fakes.FakeTilesLoader{}
+			manifestsLoader = &applychangesfakes.FakeManifestsLoader{
+				LoadAllDeployedStub: loadAllManifestsStub(manifest.Manifests{}, nil),
+				LoadAllStagedStub:   loadAllManifestsStub(manifest.Manifests{}, nil),
+			}
+			options = applychanges.ApplyChangesOptions{TileSlugs: []string{}, NonInteractive: true, Quiet: true}
+		})
+
+		It("does not start apply changes once interrupted", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			subject := applychanges.NewApplyChangesOp(manifestsLoader, tilesLoader, mockClient, reportPrinter, installationWatcher, preDeployChecker, configDiffer, options)
+			err := subject.Execute(ctx)
+
+			Expect(err).To(MatchError("apply changes was not started: context canceled"))
+			Expect(mockClient.PostCallCount()).To(BeZero())
+		})
+
+		It("warns that the installation may have started when starting it is interrupted", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			mockClient.PostStub = func(context.Context, string, string, time.Duration) ([]byte, error) {
+				cancel()
+				return nil, errors.New("POST /api/v0/installations was cancelled")
+			}
+
+			subject := applychanges.NewApplyChangesOp(manifestsLoader, tilesLoader, mockClient, reportPrinter, installationWatcher, preDeployChecker, configDiffer, options)
+			err := subject.Execute(ctx)
+
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(HavePrefix("interrupted while starting apply changes; Ops Manager may have started the installation anyway"))
+		})
+	})
+
 })
 
-func loadAllManifestsStub(m manifest.Manifests, err error) func() (manifest.Manifests, error) {
-	return func() (manifest.Manifests, error) {
+func loadAllManifestsStub(m manifest.Manifests, err error) func(context.Context) (manifest.Manifests, error) {
+	return func(context.Context) (manifest.Manifests, error) {
 		return m, err
 	}
 }
 
-func loadManifestsStub(m manifest.Manifests, err error) func([]string) (manifest.Manifests, error) {
-	return func([]string) (manifest.Manifests, error) {
+func loadManifestsStub(m manifest.Manifests, err error) func(context.Context, []string) (manifest.Manifests, error) {
+	return func(context.Context, []string) (manifest.Manifests, error) {
 		return m, err
 	}
 }
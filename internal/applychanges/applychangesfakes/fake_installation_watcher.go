@@ -0,0 +1,116 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package applychangesfakes
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pivotal-cloudops/omen/internal/installations"
+)
+
+type FakeInstallationWatcher struct {
+	WaitStub        func(context.Context, int) (installations.Installation, error)
+	waitMutex       sync.RWMutex
+	waitArgsForCall []struct {
+		arg1 context.Context
+		arg2 int
+	}
+	waitReturns struct {
+		result1 installations.Installation
+		result2 error
+	}
+	waitReturnsOnCall map[int]struct {
+		result1 installations.Installation
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeInstallationWatcher) Wait(arg1 context.Context, arg2 int) (installations.Installation, error) {
+	fake.waitMutex.Lock()
+	ret, specificReturn := fake.waitReturnsOnCall[len(fake.waitArgsForCall)]
+	fake.waitArgsForCall = append(fake.waitArgsForCall, struct {
+		arg1 context.Context
+		arg2 int
+	}{arg1, arg2})
+	fake.recordInvocation("Wait", []interface{}{arg1, arg2})
+	fake.waitMutex.Unlock()
+	if fake.WaitStub != nil {
+		return fake.WaitStub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.waitReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeInstallationWatcher) WaitCallCount() int {
+	fake.waitMutex.RLock()
+	defer fake.waitMutex.RUnlock()
+	return len(fake.waitArgsForCall)
+}
+
+func (fake *FakeInstallationWatcher) WaitCalls(stub func(context.Context, int) (installations.Installation, error)) {
+	fake.waitMutex.Lock()
+	defer fake.waitMutex.Unlock()
+	fake.WaitStub = stub
+}
+
+func (fake *FakeInstallationWatcher) WaitArgsForCall(i int) (context.Context, int) {
+	fake.waitMutex.RLock()
+	defer fake.waitMutex.RUnlock()
+	argsForCall := fake.waitArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeInstallationWatcher) WaitReturns(result1 installations.Installation, result2 error) {
+	fake.waitMutex.Lock()
+	defer fake.waitMutex.Unlock()
+	fake.WaitStub = nil
+	fake.waitReturns = struct {
+		result1 installations.Installation
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeInstallationWatcher) WaitReturnsOnCall(i int, result1 installations.Installation, result2 error) {
+	fake.waitMutex.Lock()
+	defer fake.waitMutex.Unlock()
+	fake.WaitStub = nil
+	if fake.waitReturnsOnCall == nil {
+		fake.waitReturnsOnCall = make(map[int]struct {
+			result1 installations.Installation
+			result2 error
+		})
+	}
+	fake.waitReturnsOnCall[i] = struct {
+		result1 installations.Installation
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeInstallationWatcher) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.waitMutex.RLock()
+	defer fake.waitMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeInstallationWatcher) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
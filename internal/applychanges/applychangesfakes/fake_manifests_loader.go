@@ -2,16 +2,19 @@
 package applychangesfakes
 
 import (
+	"context"
 	"sync"
 
 	"github.com/pivotal-cloudops/omen/internal/manifest"
 )
 
 type FakeManifestsLoader struct {
-	LoadAllDeployedStub        func() (manifest.Manifests, error)
+	LoadAllDeployedStub        func(context.Context) (manifest.Manifests, error)
 	loadAllDeployedMutex       sync.RWMutex
-	loadAllDeployedArgsForCall []struct{}
-	loadAllDeployedReturns     struct {
+	loadAllDeployedArgsForCall []struct {
+		arg1 context.Context
+	}
+	loadAllDeployedReturns struct {
 		result1 manifest.Manifests
 		result2 error
 	}
@@ -19,10 +22,12 @@ type FakeManifestsLoader struct {
 		result1 manifest.Manifests
 		result2 error
 	}
-	LoadAllStagedStub        func() (manifest.Manifests, error)
+	LoadAllStagedStub        func(context.Context) (manifest.Manifests, error)
 	loadAllStagedMutex       sync.RWMutex
-	loadAllStagedArgsForCall []struct{}
-	loadAllStagedReturns     struct {
+	loadAllStagedArgsForCall []struct {
+		arg1 context.Context
+	}
+	loadAllStagedReturns struct {
 		result1 manifest.Manifests
 		result2 error
 	}
@@ -30,10 +35,11 @@ type FakeManifestsLoader struct {
 		result1 manifest.Manifests
 		result2 error
 	}
-	LoadDeployedStub        func(tileGuids []string) (manifest.Manifests, error)
+	LoadDeployedStub        func(context.Context, []string) (manifest.Manifests, error)
 	loadDeployedMutex       sync.RWMutex
 	loadDeployedArgsForCall []struct {
-		tileGuids []string
+		arg1 context.Context
+		arg2 []string
 	}
 	loadDeployedReturns struct {
 		result1 manifest.Manifests
@@ -43,10 +49,11 @@ type FakeManifestsLoader struct {
 		result1 manifest.Manifests
 		result2 error
 	}
-	LoadStagedStub        func(tileGuids []string) (manifest.Manifests, error)
+	LoadStagedStub        func(context.Context, []string) (manifest.Manifests, error)
 	loadStagedMutex       sync.RWMutex
 	loadStagedArgsForCall []struct {
-		tileGuids []string
+		arg1 context.Context
+		arg2 []string
 	}
 	loadStagedReturns struct {
 		result1 manifest.Manifests
@@ -60,19 +67,22 @@ type FakeManifestsLoader struct {
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *FakeManifestsLoader) LoadAllDeployed() (manifest.Manifests, error) {
+func (fake *FakeManifestsLoader) LoadAllDeployed(arg1 context.Context) (manifest.Manifests, error) {
 	fake.loadAllDeployedMutex.Lock()
 	ret, specificReturn := fake.loadAllDeployedReturnsOnCall[len(fake.loadAllDeployedArgsForCall)]
-	fake.loadAllDeployedArgsForCall = append(fake.loadAllDeployedArgsForCall, struct{}{})
-	fake.recordInvocation("LoadAllDeployed", []interface{}{})
+	fake.loadAllDeployedArgsForCall = append(fake.loadAllDeployedArgsForCall, struct {
+		arg1 context.Context
+	}{arg1})
+	fake.recordInvocation("LoadAllDeployed", []interface{}{arg1})
 	fake.loadAllDeployedMutex.Unlock()
 	if fake.LoadAllDeployedStub != nil {
-		return fake.LoadAllDeployedStub()
+		return fake.LoadAllDeployedStub(arg1)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	return fake.loadAllDeployedReturns.result1, fake.loadAllDeployedReturns.result2
+	fakeReturns := fake.loadAllDeployedReturns
+	return fakeReturns.result1, fakeReturns.result2
 }
 
 func (fake *FakeManifestsLoader) LoadAllDeployedCallCount() int {
@@ -81,7 +91,22 @@ func (fake *FakeManifestsLoader) LoadAllDeployedCallCount() int {
 	return len(fake.loadAllDeployedArgsForCall)
 }
 
+func (fake *FakeManifestsLoader) LoadAllDeployedCalls(stub func(context.Context) (manifest.Manifests, error)) {
+	fake.loadAllDeployedMutex.Lock()
+	defer fake.loadAllDeployedMutex.Unlock()
+	fake.LoadAllDeployedStub = stub
+}
+
+func (fake *FakeManifestsLoader) LoadAllDeployedArgsForCall(i int) context.Context {
+	fake.loadAllDeployedMutex.RLock()
+	defer fake.loadAllDeployedMutex.RUnlock()
+	argsForCall := fake.loadAllDeployedArgsForCall[i]
+	return argsForCall.arg1
+}
+
 func (fake *FakeManifestsLoader) LoadAllDeployedReturns(result1 manifest.Manifests, result2 error) {
+	fake.loadAllDeployedMutex.Lock()
+	defer fake.loadAllDeployedMutex.Unlock()
 	fake.LoadAllDeployedStub = nil
 	fake.loadAllDeployedReturns = struct {
 		result1 manifest.Manifests
@@ -90,6 +115,8 @@ func (fake *FakeManifestsLoader) LoadAllDeployedReturns(result1 manifest.Manifes
 }
 
 func (fake *FakeManifestsLoader) LoadAllDeployedReturnsOnCall(i int, result1 manifest.Manifests, result2 error) {
+	fake.loadAllDeployedMutex.Lock()
+	defer fake.loadAllDeployedMutex.Unlock()
 	fake.LoadAllDeployedStub = nil
 	if fake.loadAllDeployedReturnsOnCall == nil {
 		fake.loadAllDeployedReturnsOnCall = make(map[int]struct {
@@ -103,19 +130,22 @@ func (fake *FakeManifestsLoader) LoadAllDeployedReturnsOnCall(i int, result1 man
 	}{result1, result2}
 }
 
-func (fake *FakeManifestsLoader) LoadAllStaged() (manifest.Manifests, error) {
+func (fake *FakeManifestsLoader) LoadAllStaged(arg1 context.Context) (manifest.Manifests, error) {
 	fake.loadAllStagedMutex.Lock()
 	ret, specificReturn := fake.loadAllStagedReturnsOnCall[len(fake.loadAllStagedArgsForCall)]
-	fake.loadAllStagedArgsForCall = append(fake.loadAllStagedArgsForCall, struct{}{})
-	fake.recordInvocation("LoadAllStaged", []interface{}{})
+	fake.loadAllStagedArgsForCall = append(fake.loadAllStagedArgsForCall, struct {
+		arg1 context.Context
+	}{arg1})
+	fake.recordInvocation("LoadAllStaged", []interface{}{arg1})
 	fake.loadAllStagedMutex.Unlock()
 	if fake.LoadAllStagedStub != nil {
-		return fake.LoadAllStagedStub()
+		return fake.LoadAllStagedStub(arg1)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	return fake.loadAllStagedReturns.result1, fake.loadAllStagedReturns.result2
+	fakeReturns := fake.loadAllStagedReturns
+	return fakeReturns.result1, fakeReturns.result2
 }
 
 func (fake *FakeManifestsLoader) LoadAllStagedCallCount() int {
@@ -124,7 +154,22 @@ func (fake *FakeManifestsLoader) LoadAllStagedCallCount() int {
 	return len(fake.loadAllStagedArgsForCall)
 }
 
+func (fake *FakeManifestsLoader) LoadAllStagedCalls(stub func(context.Context) (manifest.Manifests, error)) {
+	fake.loadAllStagedMutex.Lock()
+	defer fake.loadAllStagedMutex.Unlock()
+	fake.LoadAllStagedStub = stub
+}
+
+func (fake *FakeManifestsLoader) LoadAllStagedArgsForCall(i int) context.Context {
+	fake.loadAllStagedMutex.RLock()
+	defer fake.loadAllStagedMutex.RUnlock()
+	argsForCall := fake.loadAllStagedArgsForCall[i]
+	return argsForCall.arg1
+}
+
 func (fake *FakeManifestsLoader) LoadAllStagedReturns(result1 manifest.Manifests, result2 error) {
+	fake.loadAllStagedMutex.Lock()
+	defer fake.loadAllStagedMutex.Unlock()
 	fake.LoadAllStagedStub = nil
 	fake.loadAllStagedReturns = struct {
 		result1 manifest.Manifests
@@ -133,6 +178,8 @@ func (fake *FakeManifestsLoader) LoadAllStagedReturns(result1 manifest.Manifests
 }
 
 func (fake *FakeManifestsLoader) LoadAllStagedReturnsOnCall(i int, result1 manifest.Manifests, result2 error) {
+	fake.loadAllStagedMutex.Lock()
+	defer fake.loadAllStagedMutex.Unlock()
 	fake.LoadAllStagedStub = nil
 	if fake.loadAllStagedReturnsOnCall == nil {
 		fake.loadAllStagedReturnsOnCall = make(map[int]struct {
@@ -146,26 +193,28 @@ func (fake *FakeManifestsLoader) LoadAllStagedReturnsOnCall(i int, result1 manif
 	}{result1, result2}
 }
 
-func (fake *FakeManifestsLoader) LoadDeployed(tileGuids []string) (manifest.Manifests, error) {
-	var tileGuidsCopy []string
-	if tileGuids != nil {
-		tileGuidsCopy = make([]string, len(tileGuids))
-		copy(tileGuidsCopy, tileGuids)
+func (fake *FakeManifestsLoader) LoadDeployed(arg1 context.Context, arg2 []string) (manifest.Manifests, error) {
+	var arg2Copy []string
+	if arg2 != nil {
+		arg2Copy = make([]string, len(arg2))
+		copy(arg2Copy, arg2)
 	}
 	fake.loadDeployedMutex.Lock()
 	ret, specificReturn := fake.loadDeployedReturnsOnCall[len(fake.loadDeployedArgsForCall)]
 	fake.loadDeployedArgsForCall = append(fake.loadDeployedArgsForCall, struct {
-		tileGuids []string
-	}{tileGuidsCopy})
-	fake.recordInvocation("LoadDeployed", []interface{}{tileGuidsCopy})
+		arg1 context.Context
+		arg2 []string
+	}{arg1, arg2Copy})
+	fake.recordInvocation("LoadDeployed", []interface{}{arg1, arg2Copy})
 	fake.loadDeployedMutex.Unlock()
 	if fake.LoadDeployedStub != nil {
-		return fake.LoadDeployedStub(tileGuids)
+		return fake.LoadDeployedStub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	return fake.loadDeployedReturns.result1, fake.loadDeployedReturns.result2
+	fakeReturns := fake.loadDeployedReturns
+	return fakeReturns.result1, fakeReturns.result2
 }
 
 func (fake *FakeManifestsLoader) LoadDeployedCallCount() int {
@@ -174,13 +223,22 @@ func (fake *FakeManifestsLoader) LoadDeployedCallCount() int {
 	return len(fake.loadDeployedArgsForCall)
 }
 
-func (fake *FakeManifestsLoader) LoadDeployedArgsForCall(i int) []string {
+func (fake *FakeManifestsLoader) LoadDeployedCalls(stub func(context.Context, []string) (manifest.Manifests, error)) {
+	fake.loadDeployedMutex.Lock()
+	defer fake.loadDeployedMutex.Unlock()
+	fake.LoadDeployedStub = stub
+}
+
+func (fake *FakeManifestsLoader) LoadDeployedArgsForCall(i int) (context.Context, []string) {
 	fake.loadDeployedMutex.RLock()
 	defer fake.loadDeployedMutex.RUnlock()
-	return fake.loadDeployedArgsForCall[i].tileGuids
+	argsForCall := fake.loadDeployedArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
 }
 
 func (fake *FakeManifestsLoader) LoadDeployedReturns(result1 manifest.Manifests, result2 error) {
+	fake.loadDeployedMutex.Lock()
+	defer fake.loadDeployedMutex.Unlock()
 	fake.LoadDeployedStub = nil
 	fake.loadDeployedReturns = struct {
 		result1 manifest.Manifests
@@ -189,6 +247,8 @@ func (fake *FakeManifestsLoader) LoadDeployedReturns(result1 manifest.Manifests,
 }
 
 func (fake *FakeManifestsLoader) LoadDeployedReturnsOnCall(i int, result1 manifest.Manifests, result2 error) {
+	fake.loadDeployedMutex.Lock()
+	defer fake.loadDeployedMutex.Unlock()
 	fake.LoadDeployedStub = nil
 	if fake.loadDeployedReturnsOnCall == nil {
 		fake.loadDeployedReturnsOnCall = make(map[int]struct {
@@ -202,26 +262,28 @@ func (fake *FakeManifestsLoader) LoadDeployedReturnsOnCall(i int, result1 manife
 	}{result1, result2}
 }
 
-func (fake *FakeManifestsLoader) LoadStaged(tileGuids []string) (manifest.Manifests, error) {
-	var tileGuidsCopy []string
-	if tileGuids != nil {
-		tileGuidsCopy = make([]string, len(tileGuids))
-		copy(tileGuidsCopy, tileGuids)
+func (fake *FakeManifestsLoader) LoadStaged(arg1 context.Context, arg2 []string) (manifest.Manifests, error) {
+	var arg2Copy []string
+	if arg2 != nil {
+		arg2Copy = make([]string, len(arg2))
+		copy(arg2Copy, arg2)
 	}
 	fake.loadStagedMutex.Lock()
 	ret, specificReturn := fake.loadStagedReturnsOnCall[len(fake.loadStagedArgsForCall)]
 	fake.loadStagedArgsForCall = append(fake.loadStagedArgsForCall, struct {
-		tileGuids []string
-	}{tileGuidsCopy})
-	fake.recordInvocation("LoadStaged", []interface{}{tileGuidsCopy})
+		arg1 context.Context
+		arg2 []string
+	}{arg1, arg2Copy})
+	fake.recordInvocation("LoadStaged", []interface{}{arg1, arg2Copy})
 	fake.loadStagedMutex.Unlock()
 	if fake.LoadStagedStub != nil {
-		return fake.LoadStagedStub(tileGuids)
+		return fake.LoadStagedStub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	return fake.loadStagedReturns.result1, fake.loadStagedReturns.result2
+	fakeReturns := fake.loadStagedReturns
+	return fakeReturns.result1, fakeReturns.result2
 }
 
 func (fake *FakeManifestsLoader) LoadStagedCallCount() int {
@@ -230,13 +292,22 @@ func (fake *FakeManifestsLoader) LoadStagedCallCount() int {
 	return len(fake.loadStagedArgsForCall)
 }
 
-func (fake *FakeManifestsLoader) LoadStagedArgsForCall(i int) []string {
+func (fake *FakeManifestsLoader) LoadStagedCalls(stub func(context.Context, []string) (manifest.Manifests, error)) {
+	fake.loadStagedMutex.Lock()
+	defer fake.loadStagedMutex.Unlock()
+	fake.LoadStagedStub = stub
+}
+
+func (fake *FakeManifestsLoader) LoadStagedArgsForCall(i int) (context.Context, []string) {
 	fake.loadStagedMutex.RLock()
 	defer fake.loadStagedMutex.RUnlock()
-	return fake.loadStagedArgsForCall[i].tileGuids
+	argsForCall := fake.loadStagedArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
 }
 
 func (fake *FakeManifestsLoader) LoadStagedReturns(result1 manifest.Manifests, result2 error) {
+	fake.loadStagedMutex.Lock()
+	defer fake.loadStagedMutex.Unlock()
 	fake.LoadStagedStub = nil
 	fake.loadStagedReturns = struct {
 		result1 manifest.Manifests
@@ -245,6 +316,8 @@ func (fake *FakeManifestsLoader) LoadStagedReturns(result1 manifest.Manifests, r
 }
 
 func (fake *FakeManifestsLoader) LoadStagedReturnsOnCall(i int, result1 manifest.Manifests, result2 error) {
+	fake.loadStagedMutex.Lock()
+	defer fake.loadStagedMutex.Unlock()
 	fake.LoadStagedStub = nil
 	if fake.loadStagedReturnsOnCall == nil {
 		fake.loadStagedReturnsOnCall = make(map[int]struct {
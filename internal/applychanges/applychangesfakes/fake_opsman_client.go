@@ -2,17 +2,34 @@
 package applychangesfakes
 
 import (
+	"context"
 	"sync"
 	"time"
 )
 
 type FakeOpsmanClient struct {
-	PostStub        func(endpoint, data string, timeout time.Duration) ([]byte, error)
+	GetStub        func(context.Context, string, time.Duration) ([]byte, error)
+	getMutex       sync.RWMutex
+	getArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 time.Duration
+	}
+	getReturns struct {
+		result1 []byte
+		result2 error
+	}
+	getReturnsOnCall map[int]struct {
+		result1 []byte
+		result2 error
+	}
+	PostStub        func(context.Context, string, string, time.Duration) ([]byte, error)
 	postMutex       sync.RWMutex
 	postArgsForCall []struct {
-		endpoint string
-		data     string
-		timeout  time.Duration
+		arg1 context.Context
+		arg2 string
+		arg3 string
+		arg4 time.Duration
 	}
 	postReturns struct {
 		result1 []byte
@@ -26,23 +43,90 @@ type FakeOpsmanClient struct {
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *FakeOpsmanClient) Post(endpoint string, data string, timeout time.Duration) ([]byte, error) {
+func (fake *FakeOpsmanClient) Get(arg1 context.Context, arg2 string, arg3 time.Duration) ([]byte, error) {
+	fake.getMutex.Lock()
+	ret, specificReturn := fake.getReturnsOnCall[len(fake.getArgsForCall)]
+	fake.getArgsForCall = append(fake.getArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 time.Duration
+	}{arg1, arg2, arg3})
+	fake.recordInvocation("Get", []interface{}{arg1, arg2, arg3})
+	fake.getMutex.Unlock()
+	if fake.GetStub != nil {
+		return fake.GetStub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.getReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeOpsmanClient) GetCallCount() int {
+	fake.getMutex.RLock()
+	defer fake.getMutex.RUnlock()
+	return len(fake.getArgsForCall)
+}
+
+func (fake *FakeOpsmanClient) GetCalls(stub func(context.Context, string, time.Duration) ([]byte, error)) {
+	fake.getMutex.Lock()
+	defer fake.getMutex.Unlock()
+	fake.GetStub = stub
+}
+
+func (fake *FakeOpsmanClient) GetArgsForCall(i int) (context.Context, string, time.Duration) {
+	fake.getMutex.RLock()
+	defer fake.getMutex.RUnlock()
+	argsForCall := fake.getArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeOpsmanClient) GetReturns(result1 []byte, result2 error) {
+	fake.getMutex.Lock()
+	defer fake.getMutex.Unlock()
+	fake.GetStub = nil
+	fake.getReturns = struct {
+		result1 []byte
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeOpsmanClient) GetReturnsOnCall(i int, result1 []byte, result2 error) {
+	fake.getMutex.Lock()
+	defer fake.getMutex.Unlock()
+	fake.GetStub = nil
+	if fake.getReturnsOnCall == nil {
+		fake.getReturnsOnCall = make(map[int]struct {
+			result1 []byte
+			result2 error
+		})
+	}
+	fake.getReturnsOnCall[i] = struct {
+		result1 []byte
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeOpsmanClient) Post(arg1 context.Context, arg2 string, arg3 string, arg4 time.Duration) ([]byte, error) {
 	fake.postMutex.Lock()
 	ret, specificReturn := fake.postReturnsOnCall[len(fake.postArgsForCall)]
 	fake.postArgsForCall = append(fake.postArgsForCall, struct {
-		endpoint string
-		data     string
-		timeout  time.Duration
-	}{endpoint, data, timeout})
-	fake.recordInvocation("Post", []interface{}{endpoint, data, timeout})
+		arg1 context.Context
+		arg2 string
+		arg3 string
+		arg4 time.Duration
+	}{arg1, arg2, arg3, arg4})
+	fake.recordInvocation("Post", []interface{}{arg1, arg2, arg3, arg4})
 	fake.postMutex.Unlock()
 	if fake.PostStub != nil {
-		return fake.PostStub(endpoint, data, timeout)
+		return fake.PostStub(arg1, arg2, arg3, arg4)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	return fake.postReturns.result1, fake.postReturns.result2
+	fakeReturns := fake.postReturns
+	return fakeReturns.result1, fakeReturns.result2
 }
 
 func (fake *FakeOpsmanClient) PostCallCount() int {
@@ -51,13 +135,22 @@ func (fake *FakeOpsmanClient) PostCallCount() int {
 	return len(fake.postArgsForCall)
 }
 
-func (fake *FakeOpsmanClient) PostArgsForCall(i int) (string, string, time.Duration) {
+func (fake *FakeOpsmanClient) PostCalls(stub func(context.Context, string, string, time.Duration) ([]byte, error)) {
+	fake.postMutex.Lock()
+	defer fake.postMutex.Unlock()
+	fake.PostStub = stub
+}
+
+func (fake *FakeOpsmanClient) PostArgsForCall(i int) (context.Context, string, string, time.Duration) {
 	fake.postMutex.RLock()
 	defer fake.postMutex.RUnlock()
-	return fake.postArgsForCall[i].endpoint, fake.postArgsForCall[i].data, fake.postArgsForCall[i].timeout
+	argsForCall := fake.postArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
 }
 
 func (fake *FakeOpsmanClient) PostReturns(result1 []byte, result2 error) {
+	fake.postMutex.Lock()
+	defer fake.postMutex.Unlock()
 	fake.PostStub = nil
 	fake.postReturns = struct {
 		result1 []byte
@@ -66,6 +159,8 @@ func (fake *FakeOpsmanClient) PostReturns(result1 []byte, result2 error) {
 }
 
 func (fake *FakeOpsmanClient) PostReturnsOnCall(i int, result1 []byte, result2 error) {
+	fake.postMutex.Lock()
+	defer fake.postMutex.Unlock()
 	fake.PostStub = nil
 	if fake.postReturnsOnCall == nil {
 		fake.postReturnsOnCall = make(map[int]struct {
@@ -82,6 +177,8 @@ func (fake *FakeOpsmanClient) PostReturnsOnCall(i int, result1 []byte, result2 e
 func (fake *FakeOpsmanClient) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
+	fake.getMutex.RLock()
+	defer fake.getMutex.RUnlock()
 	fake.postMutex.RLock()
 	defer fake.postMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
@@ -0,0 +1,121 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package applychangesfakes
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pivotal-cloudops/omen/internal/predeploy"
+)
+
+type FakePreDeployChecker struct {
+	CheckStub        func(context.Context, []string) (predeploy.Report, error)
+	checkMutex       sync.RWMutex
+	checkArgsForCall []struct {
+		arg1 context.Context
+		arg2 []string
+	}
+	checkReturns struct {
+		result1 predeploy.Report
+		result2 error
+	}
+	checkReturnsOnCall map[int]struct {
+		result1 predeploy.Report
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakePreDeployChecker) Check(arg1 context.Context, arg2 []string) (predeploy.Report, error) {
+	var arg2Copy []string
+	if arg2 != nil {
+		arg2Copy = make([]string, len(arg2))
+		copy(arg2Copy, arg2)
+	}
+	fake.checkMutex.Lock()
+	ret, specificReturn := fake.checkReturnsOnCall[len(fake.checkArgsForCall)]
+	fake.checkArgsForCall = append(fake.checkArgsForCall, struct {
+		arg1 context.Context
+		arg2 []string
+	}{arg1, arg2Copy})
+	fake.recordInvocation("Check", []interface{}{arg1, arg2Copy})
+	fake.checkMutex.Unlock()
+	if fake.CheckStub != nil {
+		return fake.CheckStub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.checkReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakePreDeployChecker) CheckCallCount() int {
+	fake.checkMutex.RLock()
+	defer fake.checkMutex.RUnlock()
+	return len(fake.checkArgsForCall)
+}
+
+func (fake *FakePreDeployChecker) CheckCalls(stub func(context.Context, []string) (predeploy.Report, error)) {
+	fake.checkMutex.Lock()
+	defer fake.checkMutex.Unlock()
+	fake.CheckStub = stub
+}
+
+func (fake *FakePreDeployChecker) CheckArgsForCall(i int) (context.Context, []string) {
+	fake.checkMutex.RLock()
+	defer fake.checkMutex.RUnlock()
+	argsForCall := fake.checkArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakePreDeployChecker) CheckReturns(result1 predeploy.Report, result2 error) {
+	fake.checkMutex.Lock()
+	defer fake.checkMutex.Unlock()
+	fake.CheckStub = nil
+	fake.checkReturns = struct {
+		result1 predeploy.Report
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakePreDeployChecker) CheckReturnsOnCall(i int, result1 predeploy.Report, result2 error) {
+	fake.checkMutex.Lock()
+	defer fake.checkMutex.Unlock()
+	fake.CheckStub = nil
+	if fake.checkReturnsOnCall == nil {
+		fake.checkReturnsOnCall = make(map[int]struct {
+			result1 predeploy.Report
+			result2 error
+		})
+	}
+	fake.checkReturnsOnCall[i] = struct {
+		result1 predeploy.Report
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakePreDeployChecker) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.checkMutex.RLock()
+	defer fake.checkMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakePreDeployChecker) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
@@ -0,0 +1,171 @@
+package applychanges
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pivotal-cloudops/omen/internal/tile"
+)
+
+type ErrandOverride struct {
+	ProductSlug string
+	Errand      string
+	Run         bool
+}
+
+type productErrands struct {
+	RunPostDeploy map[string]bool `json:"run_post_deploy"`
+}
+
+func ParseErrandOverrides(values []string, run bool) ([]ErrandOverride, error) {
+	var overrides []ErrandOverride
+	for _, v := range values {
+		parts := strings.SplitN(strings.TrimSpace(v), ":", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("invalid errand override %q, expected <product-slug>:<errand-name>", v)
+		}
+		overrides = append(overrides, ErrandOverride{ProductSlug: parts[0], Errand: parts[1], Run: run})
+	}
+	return overrides, nil
+}
+
+func (a *applyChangesOp) errandsBody(ctx context.Context, tileGuids []string) (map[string]productErrands, error) {
+	if len(a.options.ErrandOverrides) == 0 {
+		return nil, nil
+	}
+
+	tiles, err := a.tilesLoader.LoadStaged(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	body := map[string]productErrands{}
+	for _, o := range a.options.ErrandOverrides {
+		t, err := tiles.FindBySlug(o.ProductSlug)
+		if err != nil {
+			return nil, err
+		}
+
+		if !isDeployed(t, tileGuids) {
+			return nil, fmt.Errorf("errand override for %s, but %s is not being deployed", o.Errand, o.ProductSlug)
+		}
+
+		pe, ok := body[t.GUID]
+		if !ok {
+			pe = productErrands{RunPostDeploy: map[string]bool{}}
+			body[t.GUID] = pe
+		}
+
+		if run, ok := pe.RunPostDeploy[o.Errand]; ok && run != o.Run {
+			return nil, fmt.Errorf("errand %s of %s is both skipped and run", o.Errand, o.ProductSlug)
+		}
+		pe.RunPostDeploy[o.Errand] = o.Run
+	}
+
+	return body, nil
+}
+
+type stagedErrands struct {
+	Errands []struct {
+		Name       string      `json:"name"`
+		PostDeploy interface{} `json:"post_deploy"`
+	} `json:"errands"`
+}
+
+// errandsReport lists the post-deploy errands of the products being deployed
+// as they will run: their staged state unless overridden for this run.
+func (a *applyChangesOp) errandsReport(ctx context.Context, tileGuids []string) (string, error) {
+	tiles, err := a.tilesLoader.LoadStaged(ctx, false)
+	if err != nil {
+		return "", err
+	}
+
+	sb := bytes.Buffer{}
+	for _, t := range tiles.Data {
+		if !isDeployed(*t, tileGuids) {
+			continue
+		}
+
+		resp, err := a.opsmanClient.Get(ctx, fmt.Sprintf("/api/v0/staged/products/%s/errands", t.GUID), 10*time.Minute)
+		if err != nil {
+			return "", err
+		}
+
+		var staged stagedErrands
+		err = json.Unmarshal(resp, &staged)
+		if err != nil {
+			return "", err
+		}
+
+		overrides := a.errandOverrides(t.Type)
+		for _, e := range staged.Errands {
+			if run, ok := overrides[e.Name]; ok {
+				sb.WriteString(fmt.Sprintf("%s\t%s\t%s (override)\n", t.Type, e.Name, runOrSkip(run)))
+				delete(overrides, e.Name)
+			} else if e.PostDeploy != nil {
+				sb.WriteString(fmt.Sprintf("%s\t%s\t%s\n", t.Type, e.Name, postDeployState(e.PostDeploy)))
+			}
+		}
+
+		names := make([]string, 0, len(overrides))
+		for name := range overrides {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			sb.WriteString(fmt.Sprintf("%s\t%s\t%s (override)\n", t.Type, name, runOrSkip(overrides[name])))
+		}
+	}
+
+	if sb.Len() == 0 {
+		return "", nil
+	}
+	return "Post-deploy errands for this run:\n" + sb.String(), nil
+}
+
+func (a *applyChangesOp) errandOverrides(productSlug string) map[string]bool {
+	overrides := map[string]bool{}
+	for _, o := range a.options.ErrandOverrides {
+		if o.ProductSlug == productSlug {
+			overrides[o.Errand] = o.Run
+		}
+	}
+	return overrides
+}
+
+func postDeployState(postDeploy interface{}) string {
+	switch v := postDeploy.(type) {
+	case bool:
+		return runOrSkip(v)
+	case string:
+		if v == "when-changed" {
+			return "run when changed"
+		}
+		return v
+	}
+	return fmt.Sprint(postDeploy)
+}
+
+func runOrSkip(run bool) string {
+	if run {
+		return "run"
+	}
+	return "skip"
+}
+
+func isDeployed(t tile.Tile, tileGuids []string) bool {
+	if len(tileGuids) == 0 {
+		return true
+	}
+	for _, g := range tileGuids {
+		if g == t.GUID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+package applychanges
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/pivotal-cloudops/omen/internal/manifest"
+	"github.com/pivotal-cloudops/omen/internal/tile"
+)
+
+type Plan struct {
+	TileGuids         []string `json:"tile_guids"`
+	StagedFingerprint string   `json:"staged_fingerprint"`
+}
+
+// stagedState is what a plan fingerprints: the staged manifests and the
+// staged configuration of the planned products, so that staged properties,
+// errands and resources that don't show up in the manifests yet count too.
+type stagedState struct {
+	Manifests manifest.Manifests     `json:"manifests"`
+	Config    map[string]interface{} `json:"config"`
+}
+
+func NewPlan(tileGuids []string, staged manifest.Manifests, config tile.Tiles) (Plan, error) {
+	fp, err := fingerprint(staged, config)
+	if err != nil {
+		return Plan{}, err
+	}
+
+	if tileGuids == nil {
+		tileGuids = []string{}
+	}
+
+	return Plan{TileGuids: tileGuids, StagedFingerprint: fp}, nil
+}
+
+func ReadPlan(path string) (Plan, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Plan{}, err
+	}
+
+	var p Plan
+	err = json.Unmarshal(b, &p)
+	if err != nil {
+		return Plan{}, err
+	}
+
+	if p.StagedFingerprint == "" {
+		return Plan{}, fmt.Errorf("%s is not a valid plan: staged fingerprint is missing", path)
+	}
+
+	return p, nil
+}
+
+func (p Plan) Write(path string) error {
+	b, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, b, 0644)
+}
+
+func (p Plan) Verify(staged manifest.Manifests, config tile.Tiles) error {
+	fp, err := fingerprint(staged, config)
+	if err != nil {
+		return err
+	}
+
+	if fp != p.StagedFingerprint {
+		return fmt.Errorf("staged changes have drifted since the plan was made (planned %s, found %s); "+
+			"re-run apply-changes with --dry-run to review the new diff", p.StagedFingerprint, fp)
+	}
+
+	return nil
+}
+
+func fingerprint(m manifest.Manifests, config tile.Tiles) (string, error) {
+	b, err := json.Marshal(stagedState{Manifests: m, Config: config.ConfigDocument()})
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%x", sha256.Sum256(b)), nil
+}
@@ -0,0 +1,99 @@
+package applychanges
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/pivotal-cloudops/omen/internal/diff"
+)
+
+const (
+	OutputText = "text"
+	OutputJSON = "json"
+
+	DiffFormatText = "text"
+	DiffFormatJSON = "json"
+
+	StatusDryRun    = "dry-run"
+	StatusTriggered = "triggered"
+	StatusUnknown   = "unknown"
+	StatusFailed    = "failed"
+)
+
+// Result is the document apply-changes prints with --output json. Status is
+// dry-run, triggered (applied without --wait), the final installation status,
+// or unknown when the installation could not be followed to the end. When
+// apply-changes fails the status is failed, with the error. Warnings are the
+// pre-deploy verifier warnings, whether they were ignored or stopped the run.
+type Result struct {
+	InstallationID int         `json:"installation_id,omitempty"`
+	Products       []string    `json:"products"`
+	Diff           DiffSummary `json:"diff"`
+	StartedAt      *time.Time  `json:"started_at,omitempty"`
+	FinishedAt     *time.Time  `json:"finished_at,omitempty"`
+	Warnings       []string    `json:"warnings,omitempty"`
+	Status         string      `json:"status"`
+	Error          string      `json:"error,omitempty"`
+}
+
+type DiffSummary struct {
+	Additions  int `json:"additions"`
+	Removals   int `json:"removals"`
+	Suppressed int `json:"suppressed"`
+}
+
+func summarizeChanges(changes []diff.Change) DiffSummary {
+	summary := DiffSummary{}
+	for _, c := range changes {
+		switch c.Kind {
+		case diff.Added:
+			summary.Additions++
+		case diff.Removed:
+			summary.Removals++
+		case diff.Changed:
+			summary.Additions++
+			summary.Removals++
+		}
+	}
+	return summary
+}
+
+func (a *applyChangesOp) isJSON() bool {
+	return a.options.OutputFormat == OutputJSON
+}
+
+func (a *applyChangesOp) resultProducts(ctx context.Context, tileGuids []string) ([]string, error) {
+	if len(tileGuids) > 0 {
+		return tileGuids, nil
+	}
+
+	tiles, err := a.tilesLoader.LoadStaged(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	guids := []string{}
+	for _, t := range tiles.Data {
+		guids = append(guids, t.GUID)
+	}
+	return guids, nil
+}
+
+func (a *applyChangesOp) printResult(status string) error {
+	a.result.Status = status
+
+	b, err := json.MarshalIndent(a.result, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	a.reportPrinter.PrintReport(string(b))
+	a.resultPrinted = true
+	return nil
+}
+
+func now() *time.Time {
+	t := time.Now().UTC()
+	return &t
+}
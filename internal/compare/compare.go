@@ -0,0 +1,152 @@
+package compare
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/pivotal-cloudops/omen/internal/manifest"
+	"github.com/pivotal-cloudops/omen/internal/tile"
+)
+
+type tilesLoader interface {
+	LoadStaged(context.Context, bool) (tile.Tiles, error)
+}
+
+//go:generate counterfeiter . manifestsLoader
+type manifestsLoader interface {
+	LoadAllStaged(context.Context) (manifest.Manifests, error)
+}
+
+// Foundation is the staged configuration of one Ops Manager.
+type Foundation struct {
+	Name      string
+	Tiles     tile.Tiles
+	Manifests manifest.Manifests
+}
+
+func LoadFoundation(ctx context.Context, name string, tl tilesLoader, ml manifestsLoader) (Foundation, error) {
+	tiles, err := tl.LoadStaged(ctx, true)
+	if err != nil {
+		return Foundation{}, err
+	}
+
+	manifests, err := ml.LoadAllStaged(ctx)
+	if err != nil {
+		return Foundation{}, err
+	}
+
+	return Foundation{Name: name, Tiles: tiles, Manifests: manifests}, nil
+}
+
+// Document returns the configuration of the foundation with products keyed by
+// slug instead of GUID, so that two foundations can be diffed against each
+// other. Job resources are keyed by identifier and stripped of their GUIDs, and
+// references to product GUIDs in the manifests are replaced by slugs, since
+// those differ between any two foundations.
+func (f Foundation) Document() map[string]interface{} {
+	doc := f.Tiles.ConfigDocument()
+	for _, p := range doc["products"].([]interface{}) {
+		product := p.(map[string]interface{})
+		product["resources"] = resourcesByIdentifier(product["resources"])
+	}
+
+	manifests := make([]manifest.Manifest, 0, len(f.Manifests.Data))
+	for _, m := range f.Manifests.Data {
+		m.Name = f.slugOf(m.Name)
+		m.Releases = f.withSlugs(m.Releases)
+		m.Stemcells = f.withSlugs(m.Stemcells)
+		m.InstanceGroups = f.withSlugs(m.InstanceGroups)
+		m.Update = f.withSlugs(m.Update)
+		m.Variables = f.withSlugs(m.Variables)
+		manifests = append(manifests, m)
+	}
+
+	doc["manifests"] = manifests
+	doc["cloud_config"] = f.withSlugs(f.Manifests.CloudConfig)
+
+	return doc
+}
+
+// resourcesByIdentifier turns the resources of a product into a map of its
+// jobs by identifier, without their GUIDs.
+func resourcesByIdentifier(resources interface{}) interface{} {
+	r, ok := resources.(map[string]interface{})
+	if !ok {
+		return resources
+	}
+	jobs, ok := r["resources"].([]interface{})
+	if !ok {
+		return resources
+	}
+
+	byIdentifier := make(map[string]interface{}, len(jobs))
+	for i, j := range jobs {
+		job, ok := j.(map[string]interface{})
+		if !ok {
+			byIdentifier[strconv.Itoa(i)] = j
+			continue
+		}
+
+		key, ok := job["identifier"].(string)
+		if !ok {
+			key = strconv.Itoa(i)
+		}
+
+		stripped := make(map[string]interface{}, len(job))
+		for k, v := range job {
+			if k != "guid" && k != "identifier" {
+				stripped[k] = v
+			}
+		}
+		byIdentifier[key] = stripped
+	}
+	return byIdentifier
+}
+
+// withSlugs returns a copy of a manifest section with the product GUIDs in
+// its strings, e.g. deployment names in links and credential paths, replaced
+// by slugs.
+func (f Foundation) withSlugs(data interface{}) interface{} {
+	switch v := data.(type) {
+	case string:
+		for _, t := range f.Tiles.Data {
+			if t.GUID != "" {
+				v = strings.Replace(v, t.GUID, t.Type, -1)
+			}
+		}
+		return v
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			m[k] = f.withSlugs(e)
+		}
+		return m
+	case []interface{}:
+		l := make([]interface{}, len(v))
+		for i, e := range v {
+			l[i] = f.withSlugs(e)
+		}
+		return l
+	}
+	return data
+}
+
+// slugOf maps a deployment name to the slug of its tile: the tile whose GUID
+// it is, or otherwise the tile with the longest matching slug prefix.
+func (f Foundation) slugOf(deployment string) string {
+	slug := ""
+	for _, t := range f.Tiles.Data {
+		if deployment == t.GUID {
+			return t.Type
+		}
+		if (deployment == t.Type || strings.HasPrefix(deployment, t.Type+"-")) && len(t.Type) > len(slug) {
+			slug = t.Type
+		}
+	}
+
+	if slug == "" {
+		return deployment
+	}
+	return slug
+}
@@ -0,0 +1,92 @@
+package compare_test
+
+import (
+	"context"
+	"github.com/pivotal-cloudops/omen/internal/compare"
+	"github.com/pivotal-cloudops/omen/internal/compare/comparefakes"
+	"github.com/pivotal-cloudops/omen/internal/diff"
+	"github.com/pivotal-cloudops/omen/internal/fakes"
+	"github.com/pivotal-cloudops/omen/internal/manifest"
+	"github.com/pivotal-cloudops/omen/internal/tile"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Compare", func() {
+	var sandbox, production compare.Foundation
+
+	BeforeEach(func() {
+		sandbox = compare.Foundation{
+			Name: "sandbox",
+			Tiles: tile.Tiles{Data: []*tile.Tile{
+				{Type: "cf", GUID: "cf-8d4c2e5a9f1b3d7e6a20", ProductVersion: "2.1.0",
+					Properties: map[string]interface{}{".properties.router_timeout": map[string]interface{}{"value": 900}},
+					Resources: map[string]interface{}{"resources": []interface{}{
+						map[string]interface{}{"identifier": "diego_cell", "guid": "diego_cell-0f3a9c1d2b4e5f6a7b8c", "instances": 3},
+						map[string]interface{}{"identifier": "router", "guid": "router-1e2d3c4b5a6978695a4b", "instances": 1},
+					}},
+				},
+				{Type: "p-bosh", GUID: "p-bosh-3f5e7a9c1b2d4f6e8a0c"},
+			}},
+			Manifests: manifest.Manifests{Data: []manifest.Manifest{{
+				Name:      "cf-8d4c2e5a9f1b3d7e6a20",
+				Update:    map[string]interface{}{"canaries": 1},
+				Variables: []interface{}{map[string]interface{}{"name": "/p-bosh/cf-8d4c2e5a9f1b3d7e6a20/uaa_admin", "type": "password"}},
+			}}},
+		}
+
+		production = compare.Foundation{
+			Name: "production",
+			Tiles: tile.Tiles{Data: []*tile.Tile{
+				{Type: "p-bosh", GUID: "p-bosh-b7c9d1e3f5a7b9c1d3e5"},
+				{Type: "cf", GUID: "cf-2a4c6e8f0b1d3f5a7c9e", ProductVersion: "2.1.0",
+					Properties: map[string]interface{}{".properties.router_timeout": map[string]interface{}{"value": 900}},
+					Resources: map[string]interface{}{"resources": []interface{}{
+						map[string]interface{}{"identifier": "router", "guid": "router-9a8b7c6d5e4f3a2b1c0d", "instances": 4},
+						map[string]interface{}{"identifier": "diego_cell", "guid": "diego_cell-6b5a4f3e2d1c0b9a8f7e", "instances": 3},
+					}},
+				},
+			}},
+			Manifests: manifest.Manifests{Data: []manifest.Manifest{{
+				Name:      "cf-2a4c6e8f0b1d3f5a7c9e",
+				Update:    map[string]interface{}{"canaries": 1},
+				Variables: []interface{}{map[string]interface{}{"name": "/p-bosh/cf-2a4c6e8f0b1d3f5a7c9e/uaa_admin", "type": "password"}},
+			}}},
+		}
+	})
+
+	It("matches products by slug instead of GUID", func() {
+		d, err := diff.FlatDiff(sandbox.Document(), production.Document())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(d).To(Equal(`@@ products[cf] @@
+-products[cf].resources.router.instances=1
++products[cf].resources.router.instances=4
+`))
+	})
+
+	It("reports nothing but expected differences when they are ignored", func() {
+		rules := diff.IgnoreRules{{Product: "cf", Pattern: "products[*].resources.**"}}
+
+		d, err := diff.Render(sandbox.Document(), production.Document(), diff.RenderOptions{Ignore: rules})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(d).To(Equal("2 changed lines suppressed by ignore rules\n"))
+	})
+
+	It("loads the staged tiles with their configuration and the staged manifests", func() {
+		var fetchedMetadata bool
+		tl := fakes.FakeTilesLoader{
+			StagedResponseFunc: func(fetchTileMetadata bool) (tile.Tiles, error) {
+				fetchedMetadata = fetchTileMetadata
+				return sandbox.Tiles, nil
+			},
+		}
+		ml := &comparefakes.FakeManifestsLoader{}
+		ml.LoadAllStagedReturns(sandbox.Manifests, nil)
+
+		f, err := compare.LoadFoundation(context.Background(), "sandbox", tl, ml)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fetchedMetadata).To(BeTrue())
+		Expect(f).To(Equal(sandbox))
+	})
+})
@@ -0,0 +1,114 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package comparefakes
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pivotal-cloudops/omen/internal/manifest"
+)
+
+type FakeManifestsLoader struct {
+	LoadAllStagedStub        func(context.Context) (manifest.Manifests, error)
+	loadAllStagedMutex       sync.RWMutex
+	loadAllStagedArgsForCall []struct {
+		arg1 context.Context
+	}
+	loadAllStagedReturns struct {
+		result1 manifest.Manifests
+		result2 error
+	}
+	loadAllStagedReturnsOnCall map[int]struct {
+		result1 manifest.Manifests
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeManifestsLoader) LoadAllStaged(arg1 context.Context) (manifest.Manifests, error) {
+	fake.loadAllStagedMutex.Lock()
+	ret, specificReturn := fake.loadAllStagedReturnsOnCall[len(fake.loadAllStagedArgsForCall)]
+	fake.loadAllStagedArgsForCall = append(fake.loadAllStagedArgsForCall, struct {
+		arg1 context.Context
+	}{arg1})
+	fake.recordInvocation("LoadAllStaged", []interface{}{arg1})
+	fake.loadAllStagedMutex.Unlock()
+	if fake.LoadAllStagedStub != nil {
+		return fake.LoadAllStagedStub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.loadAllStagedReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeManifestsLoader) LoadAllStagedCallCount() int {
+	fake.loadAllStagedMutex.RLock()
+	defer fake.loadAllStagedMutex.RUnlock()
+	return len(fake.loadAllStagedArgsForCall)
+}
+
+func (fake *FakeManifestsLoader) LoadAllStagedCalls(stub func(context.Context) (manifest.Manifests, error)) {
+	fake.loadAllStagedMutex.Lock()
+	defer fake.loadAllStagedMutex.Unlock()
+	fake.LoadAllStagedStub = stub
+}
+
+func (fake *FakeManifestsLoader) LoadAllStagedArgsForCall(i int) context.Context {
+	fake.loadAllStagedMutex.RLock()
+	defer fake.loadAllStagedMutex.RUnlock()
+	argsForCall := fake.loadAllStagedArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeManifestsLoader) LoadAllStagedReturns(result1 manifest.Manifests, result2 error) {
+	fake.loadAllStagedMutex.Lock()
+	defer fake.loadAllStagedMutex.Unlock()
+	fake.LoadAllStagedStub = nil
+	fake.loadAllStagedReturns = struct {
+		result1 manifest.Manifests
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeManifestsLoader) LoadAllStagedReturnsOnCall(i int, result1 manifest.Manifests, result2 error) {
+	fake.loadAllStagedMutex.Lock()
+	defer fake.loadAllStagedMutex.Unlock()
+	fake.LoadAllStagedStub = nil
+	if fake.loadAllStagedReturnsOnCall == nil {
+		fake.loadAllStagedReturnsOnCall = make(map[int]struct {
+			result1 manifest.Manifests
+			result2 error
+		})
+	}
+	fake.loadAllStagedReturnsOnCall[i] = struct {
+		result1 manifest.Manifests
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeManifestsLoader) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.loadAllStagedMutex.RLock()
+	defer fake.loadAllStagedMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeManifestsLoader) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
@@ -0,0 +1,72 @@
+package configdiff
+
+import (
+	"context"
+
+	"github.com/pivotal-cloudops/omen/internal/diff"
+	"github.com/pivotal-cloudops/omen/internal/redact"
+	"github.com/pivotal-cloudops/omen/internal/tile"
+)
+
+type tilesLoader interface {
+	LoadStaged(context.Context, bool) (tile.Tiles, error)
+	LoadDeployed(context.Context, bool) (tile.Tiles, error)
+}
+
+// Differ diffs the deployed and staged properties, resources, networks and
+// errands of tiles, as configured in Ops Manager rather than as rendered into
+// BOSH manifests.
+type Differ struct {
+	tl          tilesLoader
+	options     diff.RenderOptions
+	showSecrets bool
+}
+
+func NewDiffer(tl tilesLoader, options diff.RenderOptions, showSecrets bool) Differ {
+	return Differ{tl: tl, options: options, showSecrets: showSecrets}
+}
+
+// Diff renders the configuration changes of the given tiles, or of all tiles
+// when no GUIDs are given.
+func (d Differ) Diff(ctx context.Context, tileGuids []string) (string, error) {
+	deployed, err := d.tl.LoadDeployed(ctx, true)
+	if err != nil {
+		return "", err
+	}
+
+	staged, err := d.tl.LoadStaged(ctx, true)
+	if err != nil {
+		return "", err
+	}
+
+	var before, after interface{} = only(deployed, tileGuids).ConfigDocument(), only(staged, tileGuids).ConfigDocument()
+	if !d.showSecrets {
+		before, err = redact.Redact(before)
+		if err != nil {
+			return "", err
+		}
+
+		after, err = redact.Redact(after)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return diff.Render(before, after, d.options)
+}
+
+func only(tiles tile.Tiles, tileGuids []string) tile.Tiles {
+	if len(tileGuids) == 0 {
+		return tiles
+	}
+
+	result := tile.Tiles{}
+	for _, t := range tiles.Data {
+		for _, guid := range tileGuids {
+			if t.GUID == guid {
+				result.Data = append(result.Data, t)
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,13 @@
+package configdiff_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestConfigdiff(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Configdiff Suite")
+}
@@ -0,0 +1,74 @@
+package configdiff_test
+
+import (
+	"context"
+	"errors"
+
+	"github.com/pivotal-cloudops/omen/internal/configdiff"
+	"github.com/pivotal-cloudops/omen/internal/diff"
+	"github.com/pivotal-cloudops/omen/internal/fakes"
+	"github.com/pivotal-cloudops/omen/internal/redact"
+	"github.com/pivotal-cloudops/omen/internal/tile"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Differ", func() {
+	var tl fakes.FakeTilesLoader
+
+	BeforeEach(func() {
+		tl = fakes.FakeTilesLoader{
+			DeployedResponseFunc: func(fetchTileMetadata bool) (tile.Tiles, error) {
+				Expect(fetchTileMetadata).To(BeTrue())
+				return tile.Tiles{Data: []*tile.Tile{
+					{Type: "cf", GUID: "cf-1234",
+						Properties: map[string]interface{}{".properties.secret": map[string]interface{}{"value": "old"}},
+						Networks:   map[string]interface{}{"networks_and_azs": map[string]interface{}{"singleton_availability_zone": map[string]interface{}{"name": "z1"}}},
+					},
+					{Type: "p-redis", GUID: "p-redis-5678", Errands: map[string]interface{}{"errands": []interface{}{map[string]interface{}{"name": "smoke-tests", "post_deploy": true}}}},
+				}}, nil
+			},
+			StagedResponseFunc: func(fetchTileMetadata bool) (tile.Tiles, error) {
+				Expect(fetchTileMetadata).To(BeTrue())
+				return tile.Tiles{Data: []*tile.Tile{
+					{Type: "cf", GUID: "cf-1234",
+						Properties: map[string]interface{}{".properties.secret": map[string]interface{}{"value": "new"}},
+						Networks:   map[string]interface{}{"networks_and_azs": map[string]interface{}{"singleton_availability_zone": map[string]interface{}{"name": "z2"}}},
+					},
+					{Type: "p-redis", GUID: "p-redis-5678", Errands: map[string]interface{}{"errands": []interface{}{map[string]interface{}{"name": "smoke-tests", "post_deploy": false}}}},
+				}}, nil
+			},
+		}
+	})
+
+	It("diffs the deployed and staged configuration of all tiles", func() {
+		d, err := configdiff.NewDiffer(tl, diff.RenderOptions{}, false).Diff(context.Background(), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(d).To(Equal(`@@ products[cf] @@
+-products[cf].networks_and_azs.networks_and_azs.singleton_availability_zone.name=z1
+-products[cf].properties..properties.secret.value=` + redact.Value("old") + `
++products[cf].networks_and_azs.networks_and_azs.singleton_availability_zone.name=z2
++products[cf].properties..properties.secret.value=` + redact.Value("new") + `
+@@ products[p-redis] @@
+-products[p-redis].errands.errands[smoke-tests].post_deploy=true
++products[p-redis].errands.errands[smoke-tests].post_deploy=false
+`))
+	})
+
+	It("only diffs the given tiles", func() {
+		d, err := configdiff.NewDiffer(tl, diff.RenderOptions{}, true).Diff(context.Background(), []string{"p-redis-5678"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(d).To(HavePrefix("@@ products[p-redis] @@\n"))
+		Expect(d).NotTo(ContainSubstring("products[cf]"))
+	})
+
+	It("fails when the tiles cannot be loaded", func() {
+		tl.StagedResponseFunc = func(bool) (tile.Tiles, error) {
+			return tile.Tiles{}, errors.New("boom")
+		}
+
+		_, err := configdiff.NewDiffer(tl, diff.RenderOptions{}, false).Diff(context.Background(), nil)
+		Expect(err).To(MatchError("boom"))
+	})
+})
@@ -0,0 +1,435 @@
+package configuretiles
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/pivotal-cloudops/omen/internal/diff"
+	"github.com/pivotal-cloudops/omen/internal/redact"
+	"github.com/pivotal-cloudops/omen/internal/tile"
+	"github.com/pivotal-cloudops/omen/internal/userio"
+)
+
+type ConfigureTilesOptions struct {
+	InputDir       string
+	NonInteractive bool
+	DryRun         bool
+	ShowSecrets    bool
+	RenderOptions  diff.RenderOptions
+}
+
+type tilesLoader interface {
+	LoadStaged(context.Context, bool) (tile.Tiles, error)
+}
+
+//go:generate counterfeiter . opsmanClient
+type opsmanClient interface {
+	Get(ctx context.Context, endpoint string, timeout time.Duration) ([]byte, error)
+	Put(ctx context.Context, endpoint, data string, timeout time.Duration) ([]byte, error)
+}
+
+//go:generate counterfeiter . reportPrinter
+type reportPrinter interface {
+	PrintReport(string)
+}
+
+type ConfigureTilesOp interface {
+	Execute(ctx context.Context) error
+}
+
+type configureTilesOp struct {
+	tilesLoader   tilesLoader
+	opsmanClient  opsmanClient
+	reportPrinter reportPrinter
+	options       ConfigureTilesOptions
+}
+
+type update struct {
+	endpoint string
+	body     interface{}
+}
+
+type jobList struct {
+	Jobs []struct {
+		Name string `json:"name"`
+		GUID string `json:"guid"`
+	} `json:"jobs"`
+}
+
+func NewConfigureTilesOp(tl tilesLoader, c opsmanClient, rp reportPrinter, options ConfigureTilesOptions) ConfigureTilesOp {
+	return &configureTilesOp{
+		tilesLoader:   tl,
+		opsmanClient:  c,
+		reportPrinter: rp,
+		options:       options,
+	}
+}
+
+func (o *configureTilesOp) Execute(ctx context.Context) error {
+	input, err := tile.Read(o.options.InputDir)
+	if err != nil {
+		return err
+	}
+
+	staged, err := o.tilesLoader.LoadStaged(ctx, true)
+	if err != nil {
+		return err
+	}
+
+	var (
+		before, after tile.Tiles
+		updates       []update
+	)
+	for _, in := range input.Data {
+		current, err := staged.FindBySlug(in.Type)
+		if err != nil {
+			return err
+		}
+
+		planned, u, err := o.plan(ctx, current, *in)
+		if err != nil {
+			return err
+		}
+		if len(u) == 0 {
+			continue
+		}
+
+		before.Data = append(before.Data, &current)
+		after.Data = append(after.Data, &planned)
+		updates = append(updates, u...)
+	}
+
+	if len(updates) == 0 {
+		o.reportPrinter.PrintReport("No configuration changes")
+		return nil
+	}
+
+	err = o.printDiff(before, after)
+	if err != nil {
+		return err
+	}
+
+	if o.options.DryRun {
+		return nil
+	}
+
+	if o.options.NonInteractive == false {
+		proceed := userio.GetConfirmation("Do you wish to continue (y/n)?")
+
+		if proceed == false {
+			fmt.Println("Cancelled configure tiles")
+			return nil
+		}
+	}
+
+	for _, u := range updates {
+		body, err := json.Marshal(u.body)
+		if err != nil {
+			return err
+		}
+
+		_, err = o.opsmanClient.Put(ctx, u.endpoint, string(body), 10*time.Minute)
+		if err != nil {
+			return err
+		}
+	}
+
+	o.reportPrinter.PrintReport(fmt.Sprintf("Updated %d staged configuration sections", len(updates)))
+	return nil
+}
+
+func (o *configureTilesOp) printDiff(before, after tile.Tiles) error {
+	var (
+		b, a interface{} = before.ConfigDocument(), after.ConfigDocument()
+		err  error
+	)
+	if !o.options.ShowSecrets {
+		b, err = redact.Redact(b)
+		if err != nil {
+			return err
+		}
+
+		a, err = redact.Redact(a)
+		if err != nil {
+			return err
+		}
+	}
+
+	d, err := diff.Render(b, a, o.options.RenderOptions)
+	if err != nil {
+		return err
+	}
+
+	o.reportPrinter.PrintReport("Planned configuration changes:\n" + d)
+	return nil
+}
+
+// plan returns the staged tile with the configuration from the input applied,
+// along with the updates needed to get there. Sections missing from the input
+// are left alone, as are properties that can't be configured and credentials,
+// which the API only returns masked.
+func (o *configureTilesOp) plan(ctx context.Context, current tile.Tile, in tile.Tile) (tile.Tile, []update, error) {
+	planned := current
+	var updates []update
+
+	if in.Properties != nil {
+		changed := changedProperties(current.Properties, in.Properties)
+		if len(changed) > 0 {
+			planned.Properties = withProperties(current.Properties, in.Properties, changed)
+			updates = append(updates, update{
+				endpoint: fmt.Sprintf("/api/v0/staged/products/%s/properties", current.GUID),
+				body:     map[string]interface{}{"properties": changed},
+			})
+		}
+	}
+
+	if in.Networks != nil && !reflect.DeepEqual(in.Networks, current.Networks) {
+		planned.Networks = in.Networks
+		updates = append(updates, update{
+			endpoint: fmt.Sprintf("/api/v0/staged/products/%s/networks_and_azs", current.GUID),
+			body:     in.Networks,
+		})
+	}
+
+	if in.Errands != nil {
+		config := errandsConfig(in.Errands)
+		if !reflect.DeepEqual(config, errandsConfig(current.Errands)) {
+			planned.Errands = in.Errands
+			updates = append(updates, update{
+				endpoint: fmt.Sprintf("/api/v0/staged/products/%s/errands", current.GUID),
+				body:     config,
+			})
+		}
+	}
+
+	if in.Resources != nil && !reflect.DeepEqual(in.Resources, current.Resources) {
+		u, err := o.resourceUpdates(ctx, current, in.Resources)
+		if err != nil {
+			return tile.Tile{}, nil, err
+		}
+		if len(u) > 0 {
+			planned.Resources = in.Resources
+			updates = append(updates, u...)
+		}
+	}
+
+	return planned, updates, nil
+}
+
+func changedProperties(current, in map[string]interface{}) map[string]interface{} {
+	currentProps, _ := current["properties"].(map[string]interface{})
+	inProps, _ := in["properties"].(map[string]interface{})
+
+	changed := map[string]interface{}{}
+	for name, p := range inProps {
+		property, _ := p.(map[string]interface{})
+		existing, ok := currentProps[name].(map[string]interface{})
+		if !ok || existing["configurable"] != true || existing["credential"] == true {
+			continue
+		}
+
+		if existing["type"] == "collection" {
+			value := collectionValue(name, property["value"], existing["value"])
+			if !reflect.DeepEqual(value, tile.CollectionValue(name, existing["value"], false)) {
+				changed[name] = map[string]interface{}{"value": value}
+			}
+			continue
+		}
+
+		if !reflect.DeepEqual(property["value"], existing["value"]) {
+			changed[name] = map[string]interface{}{"value": property["value"]}
+		}
+	}
+	return changed
+}
+
+// collectionValue converts the items of a collection from the input like
+// om does, adding back the credential fields that were changed. Masked and
+// unchanged credentials are left out, so Ops Manager keeps the current ones
+// of the items identified by their guid.
+func collectionValue(name string, in interface{}, current interface{}) []interface{} {
+	value := tile.CollectionValue(name, in, false)
+
+	items, _ := in.([]interface{})
+	for i, item := range items {
+		fields, _ := item.(map[string]interface{})
+		existing := collectionItem(current, fields)
+
+		for fieldName, f := range fields {
+			field, _ := f.(map[string]interface{})
+			if field["credential"] != true || field["value"] == nil || isMasked(field["value"]) {
+				continue
+			}
+
+			existingField, _ := existing[fieldName].(map[string]interface{})
+			if reflect.DeepEqual(field["value"], existingField["value"]) {
+				continue
+			}
+			value[i].(map[string]interface{})[fieldName] = field["value"]
+		}
+	}
+	return value
+}
+
+// collectionItem returns the item of a staged collection with the same guid
+// as fields, if there is one.
+func collectionItem(collection interface{}, fields map[string]interface{}) map[string]interface{} {
+	guid, _ := fields["guid"].(map[string]interface{})
+	if guid["value"] == nil {
+		return nil
+	}
+
+	items, _ := collection.([]interface{})
+	for _, item := range items {
+		existing, _ := item.(map[string]interface{})
+		existingGuid, _ := existing["guid"].(map[string]interface{})
+		if reflect.DeepEqual(existingGuid["value"], guid["value"]) {
+			return existing
+		}
+	}
+	return nil
+}
+
+// isMasked tells whether a credential value is the mask the API returns in
+// place of secrets.
+func isMasked(value interface{}) bool {
+	switch v := value.(type) {
+	case string:
+		return v == "***"
+	case map[string]interface{}:
+		for _, field := range v {
+			if isMasked(field) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// withProperties returns the staged properties with the changed ones taken
+// from the input, as they are shown in the planned changes.
+func withProperties(current, in map[string]interface{}, changed map[string]interface{}) map[string]interface{} {
+	currentProps, _ := current["properties"].(map[string]interface{})
+	inProps, _ := in["properties"].(map[string]interface{})
+
+	props := map[string]interface{}{}
+	for name, p := range currentProps {
+		props[name] = p
+	}
+	for name := range changed {
+		property := map[string]interface{}{}
+		existing, _ := props[name].(map[string]interface{})
+		for k, v := range existing {
+			property[k] = v
+		}
+		inProperty, _ := inProps[name].(map[string]interface{})
+		property["value"] = inProperty["value"]
+		props[name] = property
+	}
+	return map[string]interface{}{"properties": props}
+}
+
+// errandsConfig returns the errands as the errands endpoint takes them: only
+// their names and states, without read-only fields such as their labels.
+func errandsConfig(errands map[string]interface{}) map[string]interface{} {
+	list, _ := errands["errands"].([]interface{})
+
+	config := make([]interface{}, 0, len(list))
+	for _, e := range list {
+		errand, _ := e.(map[string]interface{})
+		c := map[string]interface{}{"name": errand["name"]}
+		for _, state := range []string{"post_deploy", "pre_delete"} {
+			if v, ok := errand[state]; ok && v != nil {
+				c[state] = v
+			}
+		}
+		config = append(config, c)
+	}
+	return map[string]interface{}{"errands": config}
+}
+
+// resourceUpdates returns a resource config update for every job whose
+// resources differ from the staged ones.
+func (o *configureTilesOp) resourceUpdates(ctx context.Context, current tile.Tile, in map[string]interface{}) ([]update, error) {
+	currentJobs := jobsByIdentifier(current.Resources)
+	inJobs := jobsByIdentifier(in)
+
+	identifiers := make([]string, 0, len(inJobs))
+	for identifier := range inJobs {
+		identifiers = append(identifiers, identifier)
+	}
+	sort.Strings(identifiers)
+
+	var (
+		updates  []update
+		jobGuids map[string]string
+		err      error
+	)
+	for _, identifier := range identifiers {
+		existing, ok := currentJobs[identifier]
+		if !ok {
+			return nil, fmt.Errorf("job %s not found in product %s", identifier, current.Type)
+		}
+
+		config := tile.JobResourceConfig(inJobs[identifier])
+		if reflect.DeepEqual(config, tile.JobResourceConfig(existing)) {
+			continue
+		}
+
+		if jobGuids == nil {
+			jobGuids, err = o.jobGuids(ctx, current.GUID)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		jobGuid, ok := jobGuids[identifier]
+		if !ok {
+			return nil, fmt.Errorf("job %s in product %s has no guid", identifier, current.Type)
+		}
+
+		updates = append(updates, update{
+			endpoint: fmt.Sprintf("/api/v0/staged/products/%s/jobs/%s/resource_config", current.GUID, jobGuid),
+			body:     config,
+		})
+	}
+	return updates, nil
+}
+
+// jobGuids maps the job names of a staged product to their GUIDs, which the
+// resources don't include.
+func (o *configureTilesOp) jobGuids(ctx context.Context, productGuid string) (map[string]string, error) {
+	resp, err := o.opsmanClient.Get(ctx, fmt.Sprintf("/api/v0/staged/products/%s/jobs", productGuid), 10*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	var list jobList
+	err = json.Unmarshal(resp, &list)
+	if err != nil {
+		return nil, err
+	}
+
+	guids := map[string]string{}
+	for _, j := range list.Jobs {
+		guids[j.Name] = j.GUID
+	}
+	return guids, nil
+}
+
+func jobsByIdentifier(resources map[string]interface{}) map[string]map[string]interface{} {
+	list, _ := resources["resources"].([]interface{})
+
+	jobs := map[string]map[string]interface{}{}
+	for _, j := range list {
+		job, _ := j.(map[string]interface{})
+		if identifier, ok := job["identifier"].(string); ok {
+			jobs[identifier] = job
+		}
+	}
+	return jobs
+}
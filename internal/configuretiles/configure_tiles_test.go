@@ -0,0 +1,282 @@
+package configuretiles_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pivotal-cloudops/omen/internal/configuretiles"
+	"github.com/pivotal-cloudops/omen/internal/configuretiles/configuretilesfakes"
+	"github.com/pivotal-cloudops/omen/internal/fakes"
+	"github.com/pivotal-cloudops/omen/internal/tile"
+)
+
+const stagedProperties = `{"properties": {
+  ".properties.system_domain": {"type": "wildcard_domain", "configurable": true, "credential": false, "value": "sys.example.com"},
+  ".properties.apps_domain": {"type": "wildcard_domain", "configurable": true, "credential": false, "value": "apps.example.com"},
+  ".properties.generated_guid": {"type": "uuid", "configurable": false, "credential": false, "value": "abc"},
+  ".properties.encrypt_key": {"type": "secret", "configurable": true, "credential": true, "value": {"secret": "***"}},
+  ".properties.credhub_key_encryption_passwords": {"type": "collection", "configurable": true, "credential": false, "optional": false, "value": [
+    {
+      "guid": {"type": "uuid", "configurable": false, "credential": false, "value": "0b8a6b2c-4a8c-4d5e-9a5b-fd9ae7c24d0a", "optional": false},
+      "name": {"type": "string", "configurable": true, "credential": false, "value": "primary-key", "optional": false},
+      "key": {"type": "secret", "configurable": true, "credential": true, "value": {"secret": "***"}, "optional": false},
+      "primary": {"type": "boolean", "configurable": true, "credential": false, "value": true, "optional": false}
+    }
+  ]}
+}}`
+
+const stagedResources = `{"resources": [
+  {"identifier": "router", "description": "", "instances": 2, "instances_best_fit": 3, "instance_type_id": "", "instance_type_best_fit": "micro"},
+  {"identifier": "mysql", "description": "", "instances": 1, "instances_best_fit": 1, "instance_type_id": "", "instance_type_best_fit": "micro", "persistent_disk_mb": 10240, "persistent_disk_best_fit": 10240}
+]}`
+
+const stagedJobs = `{"jobs": [
+  {"name": "router", "guid": "router-9d4f1ec1e1e5a6f8a7b2"},
+  {"name": "mysql", "guid": "mysql-7c2b0f3e5d1a9e8b6c4d"}
+]}`
+
+const stagedNetworks = `{"networks_and_azs": {"network": {"name": "ert-network"}}}`
+
+const stagedErrands = `{"errands": [{"name": "smoke-tests", "label": "Smoke Test Errand", "post_deploy": true, "pre_delete": null}]}`
+
+var _ = Describe("Configure tiles", func() {
+	var (
+		tmpdir        string
+		staged        tile.Tiles
+		tilesLoader   fakes.FakeTilesLoader
+		opsmanClient  *configuretilesfakes.FakeOpsmanClient
+		reportPrinter *configuretilesfakes.FakeReportPrinter
+		options       configuretiles.ConfigureTilesOptions
+	)
+
+	writeFile := func(name string, content string) {
+		dir := filepath.Join(tmpdir, "cf")
+		Expect(os.MkdirAll(dir, 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644)).To(Succeed())
+	}
+
+	unmarshal := func(content string) map[string]interface{} {
+		var m map[string]interface{}
+		Expect(json.Unmarshal([]byte(content), &m)).To(Succeed())
+		return m
+	}
+
+	BeforeEach(func() {
+		var err error
+		tmpdir, err = ioutil.TempDir("", "")
+		Expect(err).NotTo(HaveOccurred())
+
+		staged = tile.Tiles{Data: []*tile.Tile{{
+			GUID:       "cf-1234",
+			Type:       "cf",
+			Properties: unmarshal(stagedProperties),
+			Resources:  unmarshal(stagedResources),
+			Networks:   unmarshal(stagedNetworks),
+			Errands:    unmarshal(stagedErrands),
+		}}}
+
+		tilesLoader = fakes.FakeTilesLoader{
+			StagedResponseFunc: func(bool) (tile.Tiles, error) {
+				return staged, nil
+			},
+		}
+		opsmanClient = &configuretilesfakes.FakeOpsmanClient{}
+		opsmanClient.GetReturns([]byte(stagedJobs), nil)
+		reportPrinter = &configuretilesfakes.FakeReportPrinter{}
+		options = configuretiles.ConfigureTilesOptions{InputDir: tmpdir, NonInteractive: true}
+
+		writeFile("properties.json", stagedProperties)
+		writeFile("resources.json", stagedResources)
+		writeFile("networks_and_azs.json", stagedNetworks)
+		writeFile("errands.json", stagedErrands)
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tmpdir)
+	})
+
+	execute := func() error {
+		return configuretiles.NewConfigureTilesOp(tilesLoader, opsmanClient, reportPrinter, options).Execute(context.Background())
+	}
+
+	It("does nothing when the files match the staged configuration", func() {
+		Expect(execute()).To(Succeed())
+
+		Expect(opsmanClient.PutCallCount()).To(Equal(0))
+		Expect(reportPrinter.PrintReportArgsForCall(0)).To(Equal("No configuration changes"))
+	})
+
+	It("puts back the configurable properties that changed", func() {
+		writeFile("properties.json", `{"properties": {
+		  ".properties.system_domain": {"type": "wildcard_domain", "configurable": true, "credential": false, "value": "system.example.com"},
+		  ".properties.apps_domain": {"type": "wildcard_domain", "configurable": true, "credential": false, "value": "apps.example.com"},
+		  ".properties.generated_guid": {"type": "uuid", "configurable": false, "credential": false, "value": "def"},
+		  ".properties.encrypt_key": {"type": "secret", "configurable": true, "credential": true, "value": {"secret": "changed"}}
+		}}`)
+
+		Expect(execute()).To(Succeed())
+
+		Expect(reportPrinter.PrintReportArgsForCall(0)).To(ContainSubstring(
+			"-products[cf].properties.properties..properties.system_domain.value=sys.example.com\n" +
+				"+products[cf].properties.properties..properties.system_domain.value=system.example.com"))
+		Expect(reportPrinter.PrintReportArgsForCall(0)).NotTo(ContainSubstring("generated_guid"))
+
+		Expect(opsmanClient.PutCallCount()).To(Equal(1))
+		_, endpoint, body, _ := opsmanClient.PutArgsForCall(0)
+		Expect(endpoint).To(Equal("/api/v0/staged/products/cf-1234/properties"))
+		Expect(body).To(MatchJSON(`{"properties": {".properties.system_domain": {"value": "system.example.com"}}}`))
+	})
+
+	It("puts back changed networks and errands", func() {
+		writeFile("networks_and_azs.json", `{"networks_and_azs": {"network": {"name": "other-network"}}}`)
+		writeFile("errands.json", `{"errands": [{"name": "smoke-tests", "label": "Smoke Test Errand", "post_deploy": false, "pre_delete": null}]}`)
+
+		Expect(execute()).To(Succeed())
+
+		Expect(opsmanClient.PutCallCount()).To(Equal(2))
+		_, endpoint, body, _ := opsmanClient.PutArgsForCall(0)
+		Expect(endpoint).To(Equal("/api/v0/staged/products/cf-1234/networks_and_azs"))
+		Expect(body).To(MatchJSON(`{"networks_and_azs": {"network": {"name": "other-network"}}}`))
+
+		_, endpoint, body, _ = opsmanClient.PutArgsForCall(1)
+		Expect(endpoint).To(Equal("/api/v0/staged/products/cf-1234/errands"))
+		Expect(body).To(MatchJSON(`{"errands": [{"name": "smoke-tests", "post_deploy": false}]}`))
+	})
+
+	It("ignores read-only errand fields", func() {
+		writeFile("errands.json", `{"errands": [{"name": "smoke-tests", "label": "Smoke Tests", "post_deploy": true}]}`)
+
+		Expect(execute()).To(Succeed())
+		Expect(opsmanClient.PutCallCount()).To(Equal(0))
+	})
+
+	It("shows secrets in the planned changes only when asked to", func() {
+		writeFile("properties.json", `{"properties": {
+		  ".properties.credhub_key_encryption_passwords": {"type": "collection", "configurable": true, "credential": false, "value": [
+		    {
+		      "guid": {"type": "uuid", "configurable": false, "credential": false, "value": "0b8a6b2c-4a8c-4d5e-9a5b-fd9ae7c24d0a"},
+		      "name": {"type": "string", "configurable": true, "credential": false, "value": "primary-key"},
+		      "key": {"type": "secret", "configurable": true, "credential": true, "value": {"secret": "new-encryption-key"}},
+		      "primary": {"type": "boolean", "configurable": true, "credential": false, "value": true}
+		    }
+		  ]}
+		}}`)
+		options.DryRun = true
+
+		Expect(execute()).To(Succeed())
+		Expect(reportPrinter.PrintReportArgsForCall(0)).NotTo(ContainSubstring("new-encryption-key"))
+
+		options.ShowSecrets = true
+		Expect(execute()).To(Succeed())
+		Expect(reportPrinter.PrintReportArgsForCall(1)).To(ContainSubstring("new-encryption-key"))
+	})
+
+	It("puts back the resource config of the jobs that changed", func() {
+		writeFile("resources.json", `{"resources": [
+		  {"identifier": "router", "description": "", "instances": 3, "instances_best_fit": 3, "instance_type_id": "", "instance_type_best_fit": "micro"},
+		  {"identifier": "mysql", "description": "", "instances": 1, "instances_best_fit": 1, "instance_type_id": "", "instance_type_best_fit": "micro", "persistent_disk_mb": 10240, "persistent_disk_best_fit": 10240}
+		]}`)
+
+		Expect(execute()).To(Succeed())
+
+		Expect(opsmanClient.GetCallCount()).To(Equal(1))
+		_, endpoint, _ := opsmanClient.GetArgsForCall(0)
+		Expect(endpoint).To(Equal("/api/v0/staged/products/cf-1234/jobs"))
+
+		Expect(opsmanClient.PutCallCount()).To(Equal(1))
+		_, endpoint, body, _ := opsmanClient.PutArgsForCall(0)
+		Expect(endpoint).To(Equal("/api/v0/staged/products/cf-1234/jobs/router-9d4f1ec1e1e5a6f8a7b2/resource_config"))
+		Expect(body).To(MatchJSON(`{"instances": 3, "instance_type": {"id": "automatic"}}`))
+	})
+
+	It("fails before updating anything when a changed job is unknown to Ops Manager", func() {
+		opsmanClient.GetReturns([]byte(`{"jobs": [{"name": "mysql", "guid": "mysql-7c2b0f3e5d1a9e8b6c4d"}]}`), nil)
+		writeFile("resources.json", `{"resources": [
+		  {"identifier": "router", "instances": 3, "instance_type_id": ""},
+		  {"identifier": "mysql", "instances": 1, "instance_type_id": "", "persistent_disk_mb": 10240}
+		]}`)
+
+		Expect(execute()).To(MatchError("job router in product cf has no guid"))
+		Expect(opsmanClient.PutCallCount()).To(Equal(0))
+	})
+
+	Describe("collection properties", func() {
+		collection := func(name string, key string) string {
+			return `{"properties": {
+			  ".properties.credhub_key_encryption_passwords": {"type": "collection", "configurable": true, "credential": false, "optional": false, "value": [
+			    {
+			      "guid": {"type": "uuid", "configurable": false, "credential": false, "value": "0b8a6b2c-4a8c-4d5e-9a5b-fd9ae7c24d0a", "optional": false},
+			      "name": {"type": "string", "configurable": true, "credential": false, "value": "` + name + `", "optional": false},
+			      "key": {"type": "secret", "configurable": true, "credential": true, "value": {"secret": "` + key + `"}, "optional": false},
+			      "primary": {"type": "boolean", "configurable": true, "credential": false, "value": true, "optional": false}
+			    }
+			  ]}
+			}}`
+		}
+
+		It("does nothing when a collection is unchanged, with its credentials masked", func() {
+			writeFile("properties.json", collection("primary-key", "***"))
+
+			Expect(execute()).To(Succeed())
+
+			Expect(opsmanClient.PutCallCount()).To(Equal(0))
+		})
+
+		It("puts back the items as plain values, keeping masked credentials out", func() {
+			writeFile("properties.json", collection("renamed-key", "***"))
+
+			Expect(execute()).To(Succeed())
+
+			Expect(opsmanClient.PutCallCount()).To(Equal(1))
+			_, _, body, _ := opsmanClient.PutArgsForCall(0)
+			Expect(body).To(MatchJSON(`{"properties": {".properties.credhub_key_encryption_passwords": {"value": [
+				{"guid": "0b8a6b2c-4a8c-4d5e-9a5b-fd9ae7c24d0a", "name": "renamed-key", "primary": true}
+			]}}}`))
+		})
+
+		It("puts back credentials that were changed", func() {
+			writeFile("properties.json", collection("primary-key", "a-new-encryption-key-of-20-chars"))
+
+			Expect(execute()).To(Succeed())
+
+			Expect(opsmanClient.PutCallCount()).To(Equal(1))
+			_, _, body, _ := opsmanClient.PutArgsForCall(0)
+			Expect(body).To(MatchJSON(`{"properties": {".properties.credhub_key_encryption_passwords": {"value": [
+				{"guid": "0b8a6b2c-4a8c-4d5e-9a5b-fd9ae7c24d0a", "name": "primary-key", "key": {"secret": "a-new-encryption-key-of-20-chars"}, "primary": true}
+			]}}}`))
+			Expect(reportPrinter.PrintReportArgsForCall(0)).NotTo(ContainSubstring("a-new-encryption-key-of-20-chars"))
+		})
+	})
+
+	It("leaves sections without a file alone", func() {
+		Expect(os.Remove(filepath.Join(tmpdir, "cf", "errands.json"))).To(Succeed())
+
+		Expect(execute()).To(Succeed())
+
+		Expect(opsmanClient.PutCallCount()).To(Equal(0))
+	})
+
+	It("only shows the changes on a dry run", func() {
+		writeFile("networks_and_azs.json", `{"networks_and_azs": {"network": {"name": "other-network"}}}`)
+		options.DryRun = true
+
+		Expect(execute()).To(Succeed())
+
+		Expect(reportPrinter.PrintReportArgsForCall(0)).To(ContainSubstring("other-network"))
+		Expect(opsmanClient.PutCallCount()).To(Equal(0))
+	})
+
+	It("fails for products that aren't staged", func() {
+		Expect(os.Rename(filepath.Join(tmpdir, "cf"), filepath.Join(tmpdir, "p-redis"))).To(Succeed())
+
+		err := execute()
+
+		Expect(err).To(MatchError("product p-redis not found"))
+		Expect(opsmanClient.PutCallCount()).To(Equal(0))
+	})
+})
@@ -0,0 +1,13 @@
+package configuretiles_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestConfigureTiles(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "ConfigureTiles Suite")
+}
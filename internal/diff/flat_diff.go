@@ -1,7 +1,7 @@
 package diff
 
 import (
-	"bytes"
+	"sort"
 	"strings"
 
 	"encoding/json"
@@ -9,44 +9,138 @@ import (
 	"github.com/kylelemons/godebug/diff"
 )
 
+// FlatDiff returns the +/- lines of the flattened objects, grouped under a
+// "@@ <group> @@" header per deployment and instance group.
 func FlatDiff(object1 interface{}, object2 interface{}) (string, error) {
-	// This marshal-unmarshal dance must be done so that the struct JSON tags
-	// of manifests are applied to the output. For example, we want the top-level
-	// key to be manifests, not Data. See manifest.Manifests for more information.
-	aJson, err := json.Marshal(object1)
-	if err != nil {
-		return "", err
+	return Render(object1, object2, RenderOptions{Style: StyleFlat})
+}
+
+type diffLine struct {
+	op   byte
+	text string
+}
+
+type groupDiff struct {
+	name  string
+	lines []diffLine
+}
+
+func (g groupDiff) changed() bool {
+	for _, l := range g.lines {
+		if l.op != ' ' {
+			return true
+		}
 	}
+	return false
+}
 
-	bJson, err := json.Marshal(object2)
+// groupedDiff returns the line diff of the flattened objects for every
+// deployment and instance group, including unchanged lines, and the number of
+// changed lines left out by the ignore rules.
+func groupedDiff(object1 interface{}, object2 interface{}, ignore IgnoreRules) ([]groupDiff, int, error) {
+	a, err := normalize(object1)
 	if err != nil {
-		return "", err
+		return nil, 0, err
 	}
 
-	var a interface{}
-	err = json.Unmarshal([]byte(aJson), &a)
+	b, err := normalize(object2)
 	if err != nil {
-		return "", err
+		return nil, 0, err
 	}
 
-	var b interface{}
-	err = json.Unmarshal([]byte(bJson), &b)
-	if err != nil {
-		return "", err
+	oldKept, oldIgnored := ignore.partition(flattenEntries(a))
+	newKept, newIgnored := ignore.partition(flattenEntries(b))
+
+	oldGroups := groupEntries(oldKept)
+	newGroups := groupEntries(newKept)
+
+	var result []groupDiff
+	for _, g := range groupNames(oldGroups, newGroups) {
+		result = append(result, groupDiff{name: g, lines: lineDiff(oldGroups[g], newGroups[g])})
+	}
+
+	suppressed := 0
+	for _, l := range lineDiff(oldIgnored, newIgnored) {
+		if l.op != ' ' {
+			suppressed++
+		}
+	}
+
+	return result, suppressed, nil
+}
+
+func lineDiff(olds []entry, news []entry) []diffLine {
+	var lines []diffLine
+	for _, c := range diff.DiffChunks(render(olds), render(news)) {
+		for _, l := range c.Deleted {
+			lines = append(lines, diffLine{op: '-', text: l})
+		}
+		for _, l := range c.Added {
+			lines = append(lines, diffLine{op: '+', text: l})
+		}
+		for _, l := range c.Equal {
+			lines = append(lines, diffLine{op: ' ', text: l})
+		}
+	}
+	return lines
+}
+
+func render(entries []entry) []string {
+	result := make([]string, 0, len(entries))
+	for _, e := range entries {
+		result = append(result, strings.TrimSuffix(e.String(), "\n"))
 	}
+	sort.Strings(result)
 
-	d := diff.Diff(Flatten(a), Flatten(b))
+	return result
+}
 
-	sb := bytes.Buffer{}
+func groupEntries(entries []entry) map[string][]entry {
+	result := map[string][]entry{}
+	for _, e := range entries {
+		g := groupOf(e.path)
+		result[g] = append(result[g], e)
+	}
+	return result
+}
 
-	for _, s := range strings.Split(d, "\n") {
-		switch {
-		case strings.HasPrefix(s, "+"):
-			sb.Write([]byte(s + "\n"))
-		case strings.HasPrefix(s, "-"):
-			sb.Write([]byte(s + "\n"))
+func groupNames(a map[string][]entry, b map[string][]entry) []string {
+	names := make([]string, 0, len(a)+len(b))
+	for g := range a {
+		names = append(names, g)
+	}
+	for g := range b {
+		if _, ok := a[g]; !ok {
+			names = append(names, g)
 		}
 	}
+	sort.Strings(names)
+
+	return names
+}
+
+// groupOf returns the deployment and, when there is one, the instance group a
+// path belongs to, e.g. manifests[cf].instance_groups[router].
+func groupOf(path []string) string {
+	if len(path) == 0 {
+		return ""
+	}
+	if len(path) > 1 && strings.HasPrefix(path[1], "instance_groups[") {
+		return path[0] + "." + path[1]
+	}
+	return path[0]
+}
+
+// This marshal-unmarshal dance must be done so that the struct JSON tags
+// of manifests are applied to the output. For example, we want the top-level
+// key to be manifests, not Data. See manifest.Manifests for more information.
+func normalize(object interface{}) (interface{}, error) {
+	j, err := json.Marshal(object)
+	if err != nil {
+		return nil, err
+	}
 
-	return sb.String(), nil
+	var result interface{}
+	err = json.Unmarshal(j, &result)
+	return result, err
 }
@@ -21,6 +21,18 @@ var _ = Describe("FlatDiff", func() {
 		Expect(err).NotTo(HaveOccurred())
 		Expect(actual).To(ContainSubstring(SIMPLE_EXPECTED))
 	})
+
+	It("groups changes per deployment and instance group", func() {
+		deployed, err := convertStrToJson(GROUPED_DEPLOYED)
+		Expect(err).ToNot(HaveOccurred())
+
+		staged, err := convertStrToJson(GROUPED_STAGED)
+		Expect(err).ToNot(HaveOccurred())
+
+		actual, err := diff.FlatDiff(deployed, staged)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(actual).To(Equal(GROUPED_EXPECTED))
+	})
 })
 
 func convertStrToJson(jsonStr string) (interface{}, error) {
@@ -40,3 +52,37 @@ const SIMPLE2 string = `{ "a": { "field": "value2" } }`
 const SIMPLE_EXPECTED string = `-a.field=value1
 +a.field=value2
 `
+
+const GROUPED_DEPLOYED string = `{
+  "manifests": [
+    {"name": "cf", "update": {"canaries": 1}, "instance_groups": [
+      {"name": "router", "jobs": [
+        {"name": "gorouter", "properties": {"port": 80}},
+        {"name": "metron_agent", "properties": {"port": 3457}}
+      ]},
+      {"name": "uaa", "instances": 2}
+    ]}
+  ]
+}`
+
+const GROUPED_STAGED string = `{
+  "manifests": [
+    {"name": "cf", "update": {"canaries": 2}, "instance_groups": [
+      {"name": "router", "jobs": [
+        {"name": "gorouter", "properties": {"port": 3457}},
+        {"name": "metron_agent", "properties": {"port": 80}}
+      ]},
+      {"name": "uaa", "instances": 2}
+    ]}
+  ]
+}`
+
+const GROUPED_EXPECTED string = `@@ manifests[cf] @@
+-manifests[cf].update.canaries=1
++manifests[cf].update.canaries=2
+@@ manifests[cf].instance_groups[router] @@
+-manifests[cf].instance_groups[router].jobs[gorouter].properties.port=80
++manifests[cf].instance_groups[router].jobs[gorouter].properties.port=3457
+-manifests[cf].instance_groups[router].jobs[metron_agent].properties.port=3457
++manifests[cf].instance_groups[router].jobs[metron_agent].properties.port=80
+`
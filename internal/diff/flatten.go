@@ -4,40 +4,68 @@ import (
 	"fmt"
 	"reflect"
 	"sort"
+	"strconv"
 	"strings"
 )
 
+// IdentityFields are the fields, in order of preference, used to key list
+// elements in flattened paths, e.g. instance_groups[router].jobs[gorouter].
+var IdentityFields = []string{"name", "id"}
+
+type entry struct {
+	path  []string
+	value interface{}
+}
+
+func (e entry) key() string {
+	return strings.Join(e.path, ".")
+}
+
+func (e entry) valueString() string {
+	if s, ok := e.value.(string); ok {
+		return strings.Join(strings.Split(s, "\n"), "")
+	}
+	return fmt.Sprintf("%v", e.value)
+}
+
+func (e entry) String() string {
+	return fmt.Sprintf("%s=%s\n", e.key(), e.valueString())
+}
+
 func Flatten(data interface{}) string {
-	result := make([]string, 0)
-	flatten(data, []string{}, &result)
+	entries := flattenEntries(data)
+
+	result := make([]string, 0, len(entries))
+	for _, e := range entries {
+		result = append(result, e.String())
+	}
 	sort.Slice(result, func(i, j int) bool {
 		a := result[i]
 		b := result[j]
 		return a < b
 	})
 
-	return fmt.Sprintf(strings.Join(result, ""))
+	return strings.Join(result, "")
+}
+
+func flattenEntries(data interface{}) []entry {
+	result := make([]entry, 0)
+	flatten(data, []string{}, &result)
+	return result
 }
 
-func flatten(data interface{}, parents []string, result *[]string) {
+func flatten(data interface{}, parents []string, result *[]entry) {
 	v := reflect.ValueOf(data)
 	switch v.Kind() {
 	case reflect.Slice:
 		for i := 0; i < v.Len(); i++ {
-			elem := reflect.ValueOf(v.Index(i).Interface())
-			pushed := false
-			switch elem.Kind() {
-			case reflect.Map:
-				m := elem.Interface().(map[string]interface{})
-				if k, ok := m["name"]; ok {
-					parents = append(parents, k.(string))
-					pushed = true
-				}
-			}
-			flatten(v.Index(i).Interface(), parents, result)
-			if pushed {
-				parents = parents[0 : len(parents)-1]
+			elem := v.Index(i).Interface()
+			id, ok := identity(elem, i)
+			if !ok {
+				flatten(elem, parents, result)
+				continue
 			}
+			flatten(elem, withIdentity(parents, id), result)
 		}
 	case reflect.Map:
 		for _, k := range v.MapKeys() {
@@ -52,13 +80,48 @@ func flatten(data interface{}, parents []string, result *[]string) {
 			parents = parents[0 : len(parents)-1]
 		}
 	default:
-		switch d := data.(type) {
-		case string:
-			d = strings.Join(strings.Split(data.(string), "\n"), "")
-			*result = append(*result, fmt.Sprintf("%v=%s\n", strings.Join(parents, "."), d))
-		default:
-			*result = append(*result, fmt.Sprintf("%v=%v\n", strings.Join(parents, "."), data))
+		path := make([]string, len(parents))
+		copy(path, parents)
+		*result = append(*result, entry{path: path, value: data})
+	}
+}
+
+// identity returns the key of a list element: the first of IdentityFields it
+// has, otherwise its index. Scalars have no identity, so lists of them are
+// compared as sets of values under the same path.
+func identity(elem interface{}, index int) (string, bool) {
+	v := reflect.ValueOf(elem)
+	switch v.Kind() {
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			break
+		}
+		for _, field := range IdentityFields {
+			value := v.MapIndex(reflect.ValueOf(field))
+			if value.IsValid() && value.Interface() != nil {
+				return fmt.Sprintf("%v", value.Interface()), true
+			}
 		}
+	case reflect.Struct:
+		for _, field := range IdentityFields {
+			value := v.FieldByNameFunc(func(name string) bool { return strings.EqualFold(name, field) })
+			if value.IsValid() {
+				return fmt.Sprintf("%v", value.Interface()), true
+			}
+		}
+	case reflect.Slice:
+	default:
+		return "", false
+	}
+	return strconv.Itoa(index), true
+}
 
+func withIdentity(parents []string, id string) []string {
+	path := make([]string, len(parents))
+	copy(path, parents)
+	if len(path) == 0 {
+		return []string{"[" + id + "]"}
 	}
+	path[len(path)-1] += "[" + id + "]"
+	return path
 }
@@ -66,6 +66,9 @@ var _ = Describe("Flatten", func() {
 		Entry("Is a float", FLAT_FLOAT, FLAT_FLOAT_EXPECTED),
 		Entry("Is a bool", FLAT_BOOL, FLAT_BOOL_EXPECTED),
 		Entry("Is a array", FLAT_ARRAY, FLAT_ARRAY_EXPECTED),
+		Entry("Is a list keyed by name", FLAT_NAMED_LIST, FLAT_NAMED_LIST_EXPECTED),
+		Entry("Is a list keyed by id", FLAT_ID_LIST, FLAT_ID_LIST_EXPECTED),
+		Entry("Is a list keyed by index", FLAT_UNNAMED_LIST, FLAT_UNNAMED_LIST_EXPECTED),
 	)
 })
 
@@ -80,10 +83,10 @@ type Outer struct {
 }
 
 const FLAT_STRUCT_EXPECTED = `Birthday=whenevs
-Person.Name=Tom
-Person.Name=james
-Person.Phone=whatevs
-Person.Phone=yokey
+Person[Tom].Name=Tom
+Person[Tom].Phone=yokey
+Person[james].Name=james
+Person[james].Phone=whatevs
 `
 
 const FLAT_SIMPLE1 string = `{ "a": { "field": "value1" } }`
@@ -142,3 +145,23 @@ const FLAT_ARRAY = `{"properties": {"fun": [
 const FLAT_ARRAY_EXPECTED = `properties.fun=GARIMA
 properties.fun=JAMES
 properties.fun=TOM`
+
+const FLAT_NAMED_LIST = `{"instance_groups": [
+{"name": "router", "jobs": [
+  {"name": "gorouter", "properties": {"port": 80}},
+  {"name": "metron_agent", "properties": {"port": 3457}}
+]}
+]}`
+const FLAT_NAMED_LIST_EXPECTED = `instance_groups[router].jobs[gorouter].name=gorouter
+instance_groups[router].jobs[gorouter].properties.port=80
+instance_groups[router].jobs[metron_agent].name=metron_agent
+instance_groups[router].jobs[metron_agent].properties.port=3457
+instance_groups[router].name=router`
+
+const FLAT_ID_LIST = `{"networks": [{"id": "net-1", "cidr": "10.0.0.0/24"}]}`
+const FLAT_ID_LIST_EXPECTED = `networks[net-1].cidr=10.0.0.0/24
+networks[net-1].id=net-1`
+
+const FLAT_UNNAMED_LIST = `{"addons": [{"include": "a"}, {"include": "b"}]}`
+const FLAT_UNNAMED_LIST_EXPECTED = `addons[0].include=a
+addons[1].include=b`
@@ -0,0 +1,175 @@
+package diff
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// IgnoreRule suppresses changes to the flattened paths matching Pattern, e.g.
+// manifests[*].update.*_watch_time. In a pattern, * matches within a single
+// path segment and a segment of ** matches any number of segments. A dot
+// matches both the dot between segments and a dot within a key, so paths with
+// dotted Ops Manager property keys can be copied from the diff output as they
+// are. When Product is set, the rule only applies to that product's
+// deployment, named by its slug or its GUID.
+type IgnoreRule struct {
+	Product string
+	Pattern string
+
+	expr *regexp.Regexp
+}
+
+type IgnoreRules []IgnoreRule
+
+// pathSeparator joins the segments of a path for matching, so that dots
+// within keys stay distinguishable from the separators.
+const pathSeparator = "\x00"
+
+const patternSeparator = `[.\x00]`
+
+var guidSuffix = regexp.MustCompile(`^-[0-9a-f]+$`)
+
+// ParseIgnoreRule parses a pattern, optionally prefixed with a product slug as
+// in "cf:manifests[*].instance_groups[*].jobs[*].properties.uaa.clients.*.id".
+func ParseIgnoreRule(s string) IgnoreRule {
+	s = strings.TrimSpace(s)
+	rule := IgnoreRule{Pattern: s}
+	if i := strings.Index(s, ":"); i > 0 && !strings.ContainsAny(s[:i], ".[*") {
+		rule = IgnoreRule{Product: s[:i], Pattern: s[i+1:]}
+	}
+	rule.expr = compilePattern(rule.Pattern)
+	return rule
+}
+
+// LoadIgnoreFile reads one rule per line, skipping blank lines and comments
+// starting with #.
+func LoadIgnoreFile(path string) (IgnoreRules, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var rules IgnoreRules
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		rules = append(rules, ParseIgnoreRule(line))
+	}
+
+	return rules, scanner.Err()
+}
+
+func (r IgnoreRule) matches(product string, path string) bool {
+	if r.Product != "" && product != r.Product {
+		if !strings.HasPrefix(product, r.Product) || !guidSuffix.MatchString(product[len(r.Product):]) {
+			return false
+		}
+	}
+
+	return r.expr.MatchString(path)
+}
+
+func (rules IgnoreRules) ignores(path []string) bool {
+	product := productOf(path)
+	joined := strings.Join(path, pathSeparator)
+	for _, r := range rules {
+		if r.matches(product, joined) {
+			return true
+		}
+	}
+	return false
+}
+
+func (rules IgnoreRules) partition(entries []entry) ([]entry, []entry) {
+	if len(rules) == 0 {
+		return entries, nil
+	}
+
+	rules = rules.compiled()
+
+	var kept, ignored []entry
+	for _, e := range entries {
+		if rules.ignores(e.path) {
+			ignored = append(ignored, e)
+		} else {
+			kept = append(kept, e)
+		}
+	}
+	return kept, ignored
+}
+
+// compiled returns the rules with their patterns compiled, for rules that
+// were not parsed with ParseIgnoreRule.
+func (rules IgnoreRules) compiled() IgnoreRules {
+	result := make(IgnoreRules, len(rules))
+	for i, r := range rules {
+		if r.expr == nil {
+			r.expr = compilePattern(r.Pattern)
+		}
+		result[i] = r
+	}
+	return result
+}
+
+// compilePattern turns a pattern into an expression matching paths joined
+// with pathSeparator.
+func compilePattern(pattern string) *regexp.Regexp {
+	segments := strings.Split(pattern, ".")
+
+	var sb bytes.Buffer
+	sb.WriteString("^")
+	for i, segment := range segments {
+		if segment == "**" {
+			switch {
+			case len(segments) == 1:
+				sb.WriteString(".*")
+			case i == 0:
+				sb.WriteString("(?:.*" + patternSeparator + ")?")
+			default:
+				sb.WriteString("(?:" + patternSeparator + ".*)?")
+			}
+			continue
+		}
+
+		if i > 0 && !(i == 1 && segments[0] == "**") {
+			sb.WriteString(patternSeparator)
+		}
+
+		expr := regexp.QuoteMeta(segment)
+		expr = strings.Replace(expr, `\*`, `[^\x00]*`, -1)
+		expr = strings.Replace(expr, `\?`, `[^\x00]`, -1)
+		sb.WriteString(expr)
+	}
+	sb.WriteString("$")
+
+	return regexp.MustCompile(sb.String())
+}
+
+// SplitPath splits a flattened path on the dots that are not inside a list
+// element's identity.
+func SplitPath(path string) []string {
+	var segments []string
+	depth := 0
+	start := 0
+	for i, c := range path {
+		switch c {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case '.':
+			if depth == 0 {
+				segments = append(segments, path[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(segments, path[start:])
+}
@@ -0,0 +1,129 @@
+package diff_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pivotal-cloudops/omen/internal/diff"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Ignore rules", func() {
+	var deployed, staged interface{}
+
+	BeforeEach(func() {
+		var err error
+		deployed, err = convertStrToJson(IGNORE_DEPLOYED)
+		Expect(err).ToNot(HaveOccurred())
+
+		staged, err = convertStrToJson(IGNORE_STAGED)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("parses rules with and without a product", func() {
+		rule := diff.ParseIgnoreRule("cf:manifests[*].update.*")
+		Expect(rule.Product).To(Equal("cf"))
+		Expect(rule.Pattern).To(Equal("manifests[*].update.*"))
+
+		rule = diff.ParseIgnoreRule(" cloud_config.** ")
+		Expect(rule.Product).To(BeEmpty())
+		Expect(rule.Pattern).To(Equal("cloud_config.**"))
+	})
+
+	It("matches paths with dotted property keys copied from the diff output", func() {
+		product := func(value int) interface{} {
+			return map[string]interface{}{"products": []interface{}{map[string]interface{}{
+				"name": "cf",
+				"properties": map[string]interface{}{"properties": map[string]interface{}{
+					".properties.foo.bar": map[string]interface{}{"value": value},
+					".properties.baz":     map[string]interface{}{"value": value},
+				}},
+			}}}
+		}
+
+		for _, pattern := range []string{
+			"products[cf].properties.properties..properties.foo.bar.value",
+			"cf:products[*].properties.properties.*.foo.bar.value",
+			"**..properties.foo.bar.*",
+		} {
+			summary, err := diff.Summarize(product(1), product(2), diff.IgnoreRules{diff.ParseIgnoreRule(pattern)})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(summary).To(Equal(diff.Summary{Additions: 1, Removals: 1, Suppressed: 2}), pattern)
+		}
+	})
+
+	It("matches product rules on the slug or the GUID only", func() {
+		rules := diff.IgnoreRules{{Product: "cf", Pattern: "**"}}
+		summary, err := diff.Summarize(map[string]interface{}{"manifests": []interface{}{map[string]interface{}{"name": "cf-enterprise", "x": 1}}},
+			map[string]interface{}{"manifests": []interface{}{map[string]interface{}{"name": "cf-enterprise", "x": 2}}}, rules)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(summary.Suppressed).To(BeZero())
+	})
+
+	It("suppresses matching paths and counts them in a footer", func() {
+		rules := diff.IgnoreRules{{Pattern: "manifests[*].update.*_watch_time"}, {Pattern: "cloud_config.**"}}
+
+		actual, err := diff.Render(deployed, staged, diff.RenderOptions{Style: diff.StyleFlat, Ignore: rules})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(actual).To(Equal(`@@ manifests[cf-1234] @@
+-manifests[cf-1234].update.canaries=1
++manifests[cf-1234].update.canaries=2
+8 changed lines suppressed by ignore rules
+`))
+	})
+
+	It("only applies product rules to that product's deployment", func() {
+		rules := diff.IgnoreRules{{Product: "p-redis", Pattern: "manifests[*].update.**"}}
+
+		summary, err := diff.Summarize(deployed, staged, rules)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(summary).To(Equal(diff.Summary{Additions: 5, Removals: 5, Suppressed: 0}))
+
+		rules = diff.IgnoreRules{{Product: "cf", Pattern: "manifests[*].update.**"}}
+
+		summary, err = diff.Summarize(deployed, staged, rules)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(summary).To(Equal(diff.Summary{Additions: 2, Removals: 2, Suppressed: 6}))
+	})
+
+	It("applies to structured diffs", func() {
+		changes, suppressed, err := diff.Structured(deployed, staged, diff.IgnoreRules{{Pattern: "**.*_watch_time"}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(suppressed).To(Equal(2))
+		Expect(changes).To(HaveLen(3))
+	})
+
+	It("loads rules from a file", func() {
+		dir, err := ioutil.TempDir("", "omen-diff-ignore")
+		Expect(err).NotTo(HaveOccurred())
+		defer os.RemoveAll(dir)
+
+		path := filepath.Join(dir, ".omen-diff-ignore")
+		err = ioutil.WriteFile(path, []byte("# Ops Manager rewrites these\ncf:manifests[*].update.*_watch_time\n\ncloud_config.**\n"), 0644)
+		Expect(err).NotTo(HaveOccurred())
+
+		rules, err := diff.LoadIgnoreFile(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rules).To(HaveLen(2))
+		Expect(rules[0].Product).To(Equal("cf"))
+		Expect(rules[0].Pattern).To(Equal("manifests[*].update.*_watch_time"))
+		Expect(rules[1].Pattern).To(Equal("cloud_config.**"))
+	})
+})
+
+const IGNORE_DEPLOYED string = `{
+  "manifests": [
+    {"name": "cf-1234", "update": {"canaries": 1, "canary_watch_time": "1000-2000", "update_watch_time": "1000-2000"}}
+  ],
+  "cloud_config": {"vm_types": [{"name": "large", "cpu": 2}, {"name": "small", "cpu": 1}]}
+}`
+
+const IGNORE_STAGED string = `{
+  "manifests": [
+    {"name": "cf-1234", "update": {"canaries": 2, "canary_watch_time": "30000-300000", "update_watch_time": "30000-300000"}}
+  ],
+  "cloud_config": {"vm_types": [{"name": "large", "cpu": 4}, {"name": "small", "cpu": 2}]}
+}`
@@ -0,0 +1,237 @@
+package diff
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+type Style string
+
+const (
+	StyleFlat       Style = "flat"
+	StyleUnified    Style = "unified"
+	StyleSideBySide Style = "side-by-side"
+)
+
+const (
+	colorRed   = "\x1b[31m"
+	colorGreen = "\x1b[32m"
+	colorCyan  = "\x1b[36m"
+	colorReset = "\x1b[0m"
+)
+
+type RenderOptions struct {
+	Style   Style
+	Context int
+	Color   bool
+	Ignore  IgnoreRules
+}
+
+type Summary struct {
+	Additions  int
+	Removals   int
+	Suppressed int
+}
+
+func ParseStyle(s string) (Style, error) {
+	switch Style(s) {
+	case StyleFlat, StyleUnified, StyleSideBySide:
+		return Style(s), nil
+	}
+	return "", fmt.Errorf("invalid diff style %q, expected %s, %s or %s", s, StyleFlat, StyleUnified, StyleSideBySide)
+}
+
+// Render diffs the flattened objects per deployment and instance group.
+// Flat output only has the changed lines; unified and side-by-side output
+// show options.Context unchanged lines around every change.
+func Render(object1 interface{}, object2 interface{}, options RenderOptions) (string, error) {
+	groups, suppressed, err := groupedDiff(object1, object2, options.Ignore)
+	if err != nil {
+		return "", err
+	}
+
+	context := options.Context
+	if options.Style == StyleFlat || options.Style == "" {
+		context = 0
+	}
+
+	sb := bytes.Buffer{}
+	for _, g := range groups {
+		if !g.changed() {
+			continue
+		}
+
+		sb.WriteString(options.paint(colorCyan, "@@ "+g.name+" @@") + "\n")
+
+		for i, h := range hunks(g.lines, context) {
+			if i > 0 && context > 0 {
+				sb.WriteString(options.paint(colorCyan, "...") + "\n")
+			}
+
+			if options.Style == StyleSideBySide {
+				options.writeSideBySide(&sb, h)
+			} else {
+				options.writeLines(&sb, h)
+			}
+		}
+	}
+
+	if suppressed > 0 {
+		sb.WriteString(fmt.Sprintf("%d changed lines suppressed by ignore rules\n", suppressed))
+	}
+
+	return sb.String(), nil
+}
+
+// Summarize counts the lines Render would show as removed and added, and the
+// changed lines left out by the ignore rules.
+func Summarize(object1 interface{}, object2 interface{}, ignore IgnoreRules) (Summary, error) {
+	groups, suppressed, err := groupedDiff(object1, object2, ignore)
+	if err != nil {
+		return Summary{}, err
+	}
+
+	summary := Summary{Suppressed: suppressed}
+	for _, g := range groups {
+		for _, l := range g.lines {
+			switch l.op {
+			case '+':
+				summary.Additions++
+			case '-':
+				summary.Removals++
+			}
+		}
+	}
+
+	return summary, nil
+}
+
+// hunks returns the runs of lines that are changed or within context lines of
+// a change.
+func hunks(lines []diffLine, context int) [][]diffLine {
+	visible := make([]bool, len(lines))
+	for i, l := range lines {
+		if l.op == ' ' {
+			continue
+		}
+		for j := i - context; j <= i+context; j++ {
+			if j >= 0 && j < len(lines) {
+				visible[j] = true
+			}
+		}
+	}
+
+	var result [][]diffLine
+	var current []diffLine
+	for i, l := range lines {
+		if visible[i] {
+			current = append(current, l)
+			continue
+		}
+		if current != nil {
+			result = append(result, current)
+			current = nil
+		}
+	}
+	if current != nil {
+		result = append(result, current)
+	}
+
+	return result
+}
+
+func (o RenderOptions) writeLines(sb *bytes.Buffer, lines []diffLine) {
+	for _, l := range lines {
+		text := string(l.op) + l.text
+		switch l.op {
+		case '-':
+			text = o.paint(colorRed, text)
+		case '+':
+			text = o.paint(colorGreen, text)
+		}
+		sb.WriteString(text + "\n")
+	}
+}
+
+type row struct {
+	left   string
+	right  string
+	marker string
+}
+
+func (o RenderOptions) writeSideBySide(sb *bytes.Buffer, lines []diffLine) {
+	rows := sideBySideRows(lines)
+
+	width := 0
+	for _, r := range rows {
+		if len(r.left) > width {
+			width = len(r.left)
+		}
+	}
+
+	for _, r := range rows {
+		left := r.left + strings.Repeat(" ", width-len(r.left))
+		right := r.right
+		switch r.marker {
+		case "<":
+			left = o.paint(colorRed, left)
+		case ">":
+			right = o.paint(colorGreen, right)
+		case "|":
+			left = o.paint(colorRed, left)
+			right = o.paint(colorGreen, right)
+		}
+		sb.WriteString(strings.TrimRight(left+" "+r.marker+" "+right, " ") + "\n")
+	}
+}
+
+// sideBySideRows pairs every run of removed lines with the added lines that
+// follow it, so a changed value ends up on a single row.
+func sideBySideRows(lines []diffLine) []row {
+	var rows []row
+	var removed, added []string
+
+	flush := func() {
+		for i := 0; i < len(removed) || i < len(added); i++ {
+			r := row{marker: "|"}
+			if i < len(removed) {
+				r.left = removed[i]
+			} else {
+				r.marker = ">"
+			}
+			if i < len(added) {
+				r.right = added[i]
+			} else {
+				r.marker = "<"
+			}
+			rows = append(rows, r)
+		}
+		removed, added = nil, nil
+	}
+
+	for _, l := range lines {
+		switch l.op {
+		case '-':
+			if len(added) > 0 {
+				flush()
+			}
+			removed = append(removed, l.text)
+		case '+':
+			added = append(added, l.text)
+		default:
+			flush()
+			rows = append(rows, row{left: l.text, right: l.text, marker: " "})
+		}
+	}
+	flush()
+
+	return rows
+}
+
+func (o RenderOptions) paint(color string, s string) string {
+	if !o.Color {
+		return s
+	}
+	return color + s + colorReset
+}
@@ -0,0 +1,103 @@
+package diff_test
+
+import (
+	"github.com/pivotal-cloudops/omen/internal/diff"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Render", func() {
+	var deployed, staged interface{}
+
+	BeforeEach(func() {
+		var err error
+		deployed, err = convertStrToJson(RENDER_DEPLOYED)
+		Expect(err).ToNot(HaveOccurred())
+
+		staged, err = convertStrToJson(RENDER_STAGED)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("renders only changed lines in the flat style", func() {
+		actual, err := diff.Render(deployed, staged, diff.RenderOptions{Style: diff.StyleFlat, Context: 3})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(actual).To(Equal(`@@ manifests[cf].instance_groups[router] @@
+-manifests[cf].instance_groups[router].instances=2
++manifests[cf].instance_groups[router].instances=3
+-manifests[cf].instance_groups[router].vm_type=small
+`))
+	})
+
+	It("renders context lines in the unified style", func() {
+		actual, err := diff.Render(deployed, staged, diff.RenderOptions{Style: diff.StyleUnified, Context: 1})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(actual).To(Equal(`@@ manifests[cf].instance_groups[router] @@
+ manifests[cf].instance_groups[router].azs=z1
+-manifests[cf].instance_groups[router].instances=2
++manifests[cf].instance_groups[router].instances=3
+ manifests[cf].instance_groups[router].name=router
+-manifests[cf].instance_groups[router].vm_type=small
+`))
+	})
+
+	It("separates changes further apart than the context", func() {
+		actual, err := diff.Render(deployed, staged, diff.RenderOptions{Style: diff.StyleUnified, Context: 0})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(actual).To(Equal(`@@ manifests[cf].instance_groups[router] @@
+-manifests[cf].instance_groups[router].instances=2
++manifests[cf].instance_groups[router].instances=3
+-manifests[cf].instance_groups[router].vm_type=small
+`))
+
+		actual, err = diff.Render(deployed, staged, diff.RenderOptions{Style: diff.StyleUnified, Context: 1})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(actual).NotTo(ContainSubstring("..."))
+	})
+
+	It("renders changed lines next to each other in the side-by-side style", func() {
+		actual, err := diff.Render(deployed, staged, diff.RenderOptions{Style: diff.StyleSideBySide, Context: 1})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(actual).To(Equal(`@@ manifests[cf].instance_groups[router] @@
+manifests[cf].instance_groups[router].azs=z1          manifests[cf].instance_groups[router].azs=z1
+manifests[cf].instance_groups[router].instances=2   | manifests[cf].instance_groups[router].instances=3
+manifests[cf].instance_groups[router].name=router     manifests[cf].instance_groups[router].name=router
+manifests[cf].instance_groups[router].vm_type=small <
+`))
+	})
+
+	It("colors removed and added lines", func() {
+		actual, err := diff.Render(deployed, staged, diff.RenderOptions{Style: diff.StyleFlat, Color: true})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(actual).To(ContainSubstring("\x1b[31m-manifests[cf].instance_groups[router].instances=2\x1b[0m\n"))
+		Expect(actual).To(ContainSubstring("\x1b[32m+manifests[cf].instance_groups[router].instances=3\x1b[0m\n"))
+		Expect(actual).To(ContainSubstring("\x1b[36m@@ manifests[cf].instance_groups[router] @@\x1b[0m\n"))
+	})
+
+	It("renders nothing for identical objects", func() {
+		actual, err := diff.Render(deployed, deployed, diff.RenderOptions{Style: diff.StyleUnified, Context: 3})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(actual).To(BeEmpty())
+	})
+
+	It("rejects unknown styles", func() {
+		_, err := diff.ParseStyle("fancy")
+		Expect(err).To(MatchError(ContainSubstring(`invalid diff style "fancy"`)))
+
+		style, err := diff.ParseStyle("side-by-side")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(style).To(Equal(diff.StyleSideBySide))
+	})
+})
+
+const RENDER_DEPLOYED string = `{
+  "manifests": [
+    {"name": "cf", "instance_groups": [{"name": "router", "azs": ["z1"], "instances": 2, "vm_type": "small"}]}
+  ]
+}`
+
+const RENDER_STAGED string = `{
+  "manifests": [
+    {"name": "cf", "instance_groups": [{"name": "router", "azs": ["z1"], "instances": 3}]}
+  ]
+}`
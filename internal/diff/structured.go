@@ -0,0 +1,148 @@
+package diff
+
+import (
+	"sort"
+	"strings"
+)
+
+type ChangeKind string
+
+const (
+	Added   ChangeKind = "added"
+	Removed ChangeKind = "removed"
+	Changed ChangeKind = "changed"
+)
+
+type Change struct {
+	Path          string      `json:"path"`
+	Product       string      `json:"product"`
+	InstanceGroup string      `json:"instance_group,omitempty"`
+	Kind          ChangeKind  `json:"kind"`
+	OldValue      interface{} `json:"old_value,omitempty"`
+	NewValue      interface{} `json:"new_value,omitempty"`
+}
+
+// Structured compares the flattened paths of both objects and returns one
+// record per difference, sorted by path. A path holding a single value on both
+// sides is reported as changed; otherwise values are added or removed. Changes
+// to ignored paths are left out and only counted.
+func Structured(object1 interface{}, object2 interface{}, ignore IgnoreRules) ([]Change, int, error) {
+	a, err := normalize(object1)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	b, err := normalize(object2)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	oldKept, oldIgnored := ignore.partition(flattenEntries(a))
+	newKept, newIgnored := ignore.partition(flattenEntries(b))
+
+	return changesBetween(oldKept, newKept), len(changesBetween(oldIgnored, newIgnored)), nil
+}
+
+func changesBetween(olds []entry, news []entry) []Change {
+	oldEntries := groupByPath(olds)
+	newEntries := groupByPath(news)
+
+	paths := make([]string, 0, len(oldEntries)+len(newEntries))
+	for p := range oldEntries {
+		paths = append(paths, p)
+	}
+	for p := range newEntries {
+		if _, ok := oldEntries[p]; !ok {
+			paths = append(paths, p)
+		}
+	}
+	sort.Strings(paths)
+
+	changes := []Change{}
+	for _, p := range paths {
+		changes = append(changes, compare(p, oldEntries[p], newEntries[p])...)
+	}
+
+	return changes
+}
+
+func compare(path string, olds []entry, news []entry) []Change {
+	parts := news
+	if len(olds) > 0 {
+		parts = olds
+	}
+	product := productOf(parts[0].path)
+	instanceGroup := instanceGroupOf(parts[0].path)
+
+	if len(olds) == 1 && len(news) == 1 {
+		if olds[0].valueString() == news[0].valueString() {
+			return nil
+		}
+		return []Change{{Path: path, Product: product, InstanceGroup: instanceGroup, Kind: Changed, OldValue: olds[0].value, NewValue: news[0].value}}
+	}
+
+	var changes []Change
+	for _, o := range without(olds, news) {
+		changes = append(changes, Change{Path: path, Product: product, InstanceGroup: instanceGroup, Kind: Removed, OldValue: o.value})
+	}
+	for _, n := range without(news, olds) {
+		changes = append(changes, Change{Path: path, Product: product, InstanceGroup: instanceGroup, Kind: Added, NewValue: n.value})
+	}
+	return changes
+}
+
+func without(entries []entry, other []entry) []entry {
+	remaining := map[string]int{}
+	for _, o := range other {
+		remaining[o.valueString()]++
+	}
+
+	var result []entry
+	for _, e := range entries {
+		if remaining[e.valueString()] > 0 {
+			remaining[e.valueString()]--
+			continue
+		}
+		result = append(result, e)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].valueString() < result[j].valueString()
+	})
+	return result
+}
+
+func groupByPath(entries []entry) map[string][]entry {
+	result := map[string][]entry{}
+	for _, e := range entries {
+		result[e.key()] = append(result[e.key()], e)
+	}
+	return result
+}
+
+func productOf(parts []string) string {
+	if len(parts) > 0 && strings.HasSuffix(parts[0], "]") {
+		return IdentityOf(parts[0])
+	}
+	if len(parts) > 0 {
+		return parts[0]
+	}
+	return ""
+}
+
+func instanceGroupOf(parts []string) string {
+	if len(parts) > 1 && strings.HasPrefix(parts[1], "instance_groups[") {
+		return IdentityOf(parts[1])
+	}
+	return ""
+}
+
+// IdentityOf returns the key of a list element segment, e.g. router for
+// instance_groups[router].
+func IdentityOf(segment string) string {
+	start := strings.Index(segment, "[")
+	if start < 0 || !strings.HasSuffix(segment, "]") {
+		return ""
+	}
+	return segment[start+1 : len(segment)-1]
+}
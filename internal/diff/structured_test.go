@@ -0,0 +1,66 @@
+package diff_test
+
+import (
+	"github.com/pivotal-cloudops/omen/internal/diff"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Structured", func() {
+	It("reports changed values", func() {
+		simple1, err := convertStrToJson(SIMPLE1)
+		Expect(err).ToNot(HaveOccurred())
+
+		simple2, err := convertStrToJson(SIMPLE2)
+		Expect(err).ToNot(HaveOccurred())
+
+		changes, _, err := diff.Structured(simple1, simple2, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changes).To(Equal([]diff.Change{
+			{Path: "a.field", Product: "a", Kind: diff.Changed, OldValue: "value1", NewValue: "value2"},
+		}))
+	})
+
+	It("reports added and removed values per product", func() {
+		deployed, err := convertStrToJson(STRUCTURED_DEPLOYED)
+		Expect(err).ToNot(HaveOccurred())
+
+		staged, err := convertStrToJson(STRUCTURED_STAGED)
+		Expect(err).ToNot(HaveOccurred())
+
+		changes, _, err := diff.Structured(deployed, staged, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changes).To(Equal([]diff.Change{
+			{Path: "cloud_config.vm_types[large].cpu", Product: "cloud_config", Kind: diff.Changed, OldValue: float64(2), NewValue: float64(4)},
+			{Path: "manifests[cf].instance_groups[router].azs", Product: "cf", InstanceGroup: "router", Kind: diff.Removed, OldValue: "z2"},
+			{Path: "manifests[cf].instance_groups[router].azs", Product: "cf", InstanceGroup: "router", Kind: diff.Added, NewValue: "z3"},
+			{Path: "manifests[cf].instance_groups[router].instances", Product: "cf", InstanceGroup: "router", Kind: diff.Changed, OldValue: float64(2), NewValue: float64(3)},
+			{Path: "manifests[p-redis].name", Product: "p-redis", Kind: diff.Added, NewValue: "p-redis"},
+		}))
+	})
+
+	It("reports nothing for identical objects", func() {
+		deployed, err := convertStrToJson(STRUCTURED_DEPLOYED)
+		Expect(err).ToNot(HaveOccurred())
+
+		changes, _, err := diff.Structured(deployed, deployed, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changes).To(BeEmpty())
+	})
+})
+
+const STRUCTURED_DEPLOYED = `{
+  "manifests": [
+    {"name": "cf", "instance_groups": [{"name": "router", "instances": 2, "azs": ["z1", "z2"]}]}
+  ],
+  "cloud_config": {"vm_types": [{"name": "large", "cpu": 2}]}
+}`
+
+const STRUCTURED_STAGED = `{
+  "manifests": [
+    {"name": "cf", "instance_groups": [{"name": "router", "instances": 3, "azs": ["z1", "z3"]}]},
+    {"name": "p-redis"}
+  ],
+  "cloud_config": {"vm_types": [{"name": "large", "cpu": 4}]}
+}`
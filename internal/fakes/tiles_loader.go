@@ -1,6 +1,8 @@
 package fakes
 
 import (
+	"context"
+
 	"github.com/pivotal-cloudops/omen/internal/tile"
 )
 
@@ -9,10 +11,13 @@ type FakeTilesLoader struct {
 	DeployedResponseFunc func(bool) (tile.Tiles, error)
 }
 
-func (f FakeTilesLoader) LoadStaged(fetchTileMetadata bool) (tile.Tiles, error) {
+func (f FakeTilesLoader) LoadStaged(ctx context.Context, fetchTileMetadata bool) (tile.Tiles, error) {
+	if f.StagedResponseFunc == nil {
+		return tile.Tiles{}, nil
+	}
 	return f.StagedResponseFunc(fetchTileMetadata)
 }
 
-func (f FakeTilesLoader) LoadDeployed(fetchTileMetadata bool) (tile.Tiles, error) {
+func (f FakeTilesLoader) LoadDeployed(ctx context.Context, fetchTileMetadata bool) (tile.Tiles, error) {
 	return f.DeployedResponseFunc(fetchTileMetadata)
 }
@@ -0,0 +1,66 @@
+package installations
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const (
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+//go:generate counterfeiter . opsmanClient
+type opsmanClient interface {
+	Get(ctx context.Context, endpoint string, timeout time.Duration) ([]byte, error)
+}
+
+//go:generate counterfeiter . reporter
+type reporter interface {
+	PrintReport(report string)
+}
+
+type Installation struct {
+	ID         int        `json:"id"`
+	Status     string     `json:"status"`
+	UserName   string     `json:"user_name,omitempty"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+type installationList struct {
+	Installations []Installation `json:"installations"`
+}
+
+type installationLogs struct {
+	Logs string `json:"logs"`
+}
+
+func getStatus(ctx context.Context, client opsmanClient, id int) (Installation, error) {
+	resp, err := client.Get(ctx, fmt.Sprintf("/api/v0/installations/%d", id), 10*time.Minute)
+	if err != nil {
+		return Installation{}, err
+	}
+
+	installation := Installation{}
+	err = json.Unmarshal(resp, &installation)
+	if err != nil {
+		return Installation{}, err
+	}
+	installation.ID = id
+
+	return installation, nil
+}
+
+// sleep waits for the interval to pass, or fails if the context is done first.
+func sleep(ctx context.Context, interval time.Duration) error {
+	select {
+	case <-time.After(interval):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,13 @@
+package installations_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestInstallations(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Installations Suite")
+}
@@ -0,0 +1,71 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package installationsfakes
+
+import (
+	"sync"
+)
+
+type FakeReporter struct {
+	PrintReportStub        func(string)
+	printReportMutex       sync.RWMutex
+	printReportArgsForCall []struct {
+		arg1 string
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeReporter) PrintReport(arg1 string) {
+	fake.printReportMutex.Lock()
+	fake.printReportArgsForCall = append(fake.printReportArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	fake.recordInvocation("PrintReport", []interface{}{arg1})
+	fake.printReportMutex.Unlock()
+	if fake.PrintReportStub != nil {
+		fake.PrintReportStub(arg1)
+	}
+}
+
+func (fake *FakeReporter) PrintReportCallCount() int {
+	fake.printReportMutex.RLock()
+	defer fake.printReportMutex.RUnlock()
+	return len(fake.printReportArgsForCall)
+}
+
+func (fake *FakeReporter) PrintReportCalls(stub func(string)) {
+	fake.printReportMutex.Lock()
+	defer fake.printReportMutex.Unlock()
+	fake.PrintReportStub = stub
+}
+
+func (fake *FakeReporter) PrintReportArgsForCall(i int) string {
+	fake.printReportMutex.RLock()
+	defer fake.printReportMutex.RUnlock()
+	argsForCall := fake.printReportArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeReporter) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.printReportMutex.RLock()
+	defer fake.printReportMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeReporter) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
@@ -0,0 +1,100 @@
+package installations
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+type RunningError struct {
+	Installation Installation
+}
+
+func (e RunningError) Error() string {
+	msg := fmt.Sprintf("installation %d is already running", e.Installation.ID)
+	if e.Installation.UserName != "" {
+		msg = fmt.Sprintf("%s (started by %s", msg, e.Installation.UserName)
+		if e.Installation.StartedAt != nil {
+			msg = fmt.Sprintf("%s at %s", msg, e.Installation.StartedAt.Format(time.RFC3339))
+		}
+		msg += ")"
+	}
+	return msg
+}
+
+type RunningCheck struct {
+	client   opsmanClient
+	reporter reporter
+	wait     bool
+	interval time.Duration
+}
+
+func NewRunningCheck(c opsmanClient, rp reporter, wait bool, interval time.Duration) RunningCheck {
+	return RunningCheck{client: c, reporter: rp, wait: wait, interval: interval}
+}
+
+// Check fails with a RunningError if Ops Manager is mid-install, or blocks
+// until Ops Manager is idle when the check was built to wait.
+func (r RunningCheck) Check(ctx context.Context) error {
+	for {
+		running, err := r.findRunning(ctx)
+		if err != nil {
+			return err
+		}
+
+		if running == nil {
+			return nil
+		}
+
+		if !r.wait {
+			return RunningError{Installation: *running}
+		}
+
+		r.reporter.PrintReport(fmt.Sprintf("Waiting for %s to finish", RunningError{Installation: *running}.Error()))
+		err = r.waitFor(ctx, running.ID)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (r RunningCheck) findRunning(ctx context.Context) (*Installation, error) {
+	resp, err := r.client.Get(ctx, "/api/v0/installations", 10*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	var list installationList
+	err = json.Unmarshal(resp, &list)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, i := range list.Installations {
+		if i.Status == StatusRunning {
+			return &i, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (r RunningCheck) waitFor(ctx context.Context, id int) error {
+	for {
+		installation, err := getStatus(ctx, r.client, id)
+		if err != nil {
+			return err
+		}
+
+		if installation.Status != StatusRunning {
+			r.reporter.PrintReport(fmt.Sprintf("Installation %d %s", id, installation.Status))
+			return nil
+		}
+
+		err = sleep(ctx, r.interval)
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package installations_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pivotal-cloudops/omen/internal/installations"
+	"github.com/pivotal-cloudops/omen/internal/installations/installationsfakes"
+)
+
+const runningInstallations = `{
+  "installations": [
+    {"id": 12, "status": "running", "user_name": "admin", "started_at": "2018-03-01T10:00:00.000Z", "finished_at": null},
+    {"id": 11, "status": "succeeded", "user_name": "admin", "started_at": "2018-02-01T10:00:00.000Z", "finished_at": "2018-02-01T11:00:00.000Z"}
+  ]
+}`
+
+const idleInstallations = `{
+  "installations": [
+    {"id": 11, "status": "succeeded", "user_name": "admin", "started_at": "2018-02-01T10:00:00.000Z", "finished_at": "2018-02-01T11:00:00.000Z"}
+  ]
+}`
+
+var _ = Describe("RunningCheck", func() {
+	var (
+		client   *installationsfakes.FakeOpsmanClient
+		reporter *installationsfakes.FakeReporter
+	)
+
+	BeforeEach(func() {
+		client = &installationsfakes.FakeOpsmanClient{}
+		reporter = &installationsfakes.FakeReporter{}
+	})
+
+	It("passes when no installation is running", func() {
+		client.GetReturns([]byte(idleInstallations), nil)
+
+		subject := installations.NewRunningCheck(client, reporter, false, time.Millisecond)
+		Expect(subject.Check(context.Background())).To(Succeed())
+
+		_, endpoint, _ := client.GetArgsForCall(0)
+		Expect(endpoint).To(Equal("/api/v0/installations"))
+	})
+
+	It("reports who started the running installation and when", func() {
+		client.GetReturns([]byte(runningInstallations), nil)
+
+		subject := installations.NewRunningCheck(client, reporter, false, time.Millisecond)
+		err := subject.Check(context.Background())
+
+		Expect(err).To(BeAssignableToTypeOf(installations.RunningError{}))
+		Expect(err).To(MatchError("installation 12 is already running (started by admin at 2018-03-01T10:00:00Z)"))
+	})
+
+	It("waits for the running installation to finish when asked to", func() {
+		client.GetReturnsOnCall(0, []byte(runningInstallations), nil)
+		client.GetReturnsOnCall(1, []byte(`{"status": "running"}`), nil)
+		client.GetReturnsOnCall(2, []byte(`{"status": "succeeded"}`), nil)
+		client.GetReturnsOnCall(3, []byte(idleInstallations), nil)
+
+		subject := installations.NewRunningCheck(client, reporter, true, time.Millisecond)
+		Expect(subject.Check(context.Background())).To(Succeed())
+
+		Expect(client.GetCallCount()).To(Equal(4))
+		_, endpoint, _ := client.GetArgsForCall(1)
+		Expect(endpoint).To(Equal("/api/v0/installations/12"))
+		Expect(reporter.PrintReportArgsForCall(0)).To(ContainSubstring("Waiting for installation 12"))
+		Expect(reporter.PrintReportArgsForCall(1)).To(Equal("Installation 12 succeeded"))
+	})
+
+	It("surfaces errors from ops manager", func() {
+		client.GetReturns(nil, errors.New("unreachable"))
+
+		subject := installations.NewRunningCheck(client, reporter, true, time.Millisecond)
+		Expect(subject.Check(context.Background())).To(MatchError("unreachable"))
+	})
+})
@@ -0,0 +1,72 @@
+package installations
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+type Watcher struct {
+	client   opsmanClient
+	out      io.Writer
+	interval time.Duration
+}
+
+func NewWatcher(c opsmanClient, out io.Writer, interval time.Duration) Watcher {
+	return Watcher{client: c, out: out, interval: interval}
+}
+
+// Wait streams the installation logs until it stops running and fails unless
+// the installation succeeded.
+func (w Watcher) Wait(ctx context.Context, id int) (Installation, error) {
+	printed := 0
+
+	for {
+		installation, err := getStatus(ctx, w.client, id)
+		if err != nil {
+			return Installation{}, err
+		}
+
+		printed, err = w.streamLogs(ctx, id, printed)
+		if err != nil {
+			return Installation{}, err
+		}
+
+		if installation.Status != StatusRunning {
+			if installation.Status != StatusSucceeded {
+				return installation, fmt.Errorf("installation %d %s", id, installation.Status)
+			}
+			return installation, nil
+		}
+
+		err = sleep(ctx, w.interval)
+		if err != nil {
+			return installation, err
+		}
+	}
+}
+
+func (w Watcher) streamLogs(ctx context.Context, id int, printed int) (int, error) {
+	resp, err := w.client.Get(ctx, fmt.Sprintf("/api/v0/installations/%d/logs", id), 10*time.Minute)
+	if err != nil {
+		return printed, err
+	}
+
+	var logs installationLogs
+	err = json.Unmarshal(resp, &logs)
+	if err != nil {
+		return printed, err
+	}
+
+	if len(logs.Logs) > printed {
+		_, err = io.WriteString(w.out, logs.Logs[printed:])
+		if err != nil {
+			return printed, err
+		}
+		printed = len(logs.Logs)
+	}
+
+	return printed, nil
+}
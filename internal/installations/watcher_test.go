@@ -0,0 +1,88 @@
+package installations_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pivotal-cloudops/omen/internal/installations"
+	"github.com/pivotal-cloudops/omen/internal/installations/installationsfakes"
+)
+
+var _ = Describe("Watcher", func() {
+	var (
+		client *installationsfakes.FakeOpsmanClient
+		out    *bytes.Buffer
+		status []string
+		logs   []string
+	)
+
+	BeforeEach(func() {
+		client = &installationsfakes.FakeOpsmanClient{}
+		out = &bytes.Buffer{}
+		status = []string{}
+		logs = []string{}
+
+		client.GetStub = func(_ context.Context, endpoint string, _ time.Duration) ([]byte, error) {
+			var next string
+			switch endpoint {
+			case "/api/v0/installations/303":
+				next, status = status[0], status[1:]
+				return []byte(`{"status":"` + next + `"}`), nil
+			case "/api/v0/installations/303/logs":
+				next, logs = logs[0], logs[1:]
+				return []byte(`{"logs":"` + next + `"}`), nil
+			}
+			return nil, errors.New("unexpected endpoint " + endpoint)
+		}
+	})
+
+	It("streams new log output until the installation succeeds", func() {
+		status = []string{"running", "running", "succeeded"}
+		logs = []string{"line 1\\n", "line 1\\nline 2\\n", "line 1\\nline 2\\nline 3\\n"}
+
+		subject := installations.NewWatcher(client, out, time.Millisecond)
+		installation, err := subject.Wait(context.Background(), 303)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(installation).To(Equal(installations.Installation{ID: 303, Status: "succeeded"}))
+		Expect(out.String()).To(Equal("line 1\nline 2\nline 3\n"))
+	})
+
+	It("returns an error when the installation fails", func() {
+		status = []string{"running", "failed"}
+		logs = []string{"starting\\n", "starting\\nboom\\n"}
+
+		subject := installations.NewWatcher(client, out, time.Millisecond)
+		installation, err := subject.Wait(context.Background(), 303)
+
+		Expect(err).To(MatchError("installation 303 failed"))
+		Expect(installation.Status).To(Equal("failed"))
+		Expect(out.String()).To(Equal("starting\nboom\n"))
+	})
+
+	It("stops waiting when the context is cancelled", func() {
+		status = []string{"running"}
+		logs = []string{"starting\\n"}
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		subject := installations.NewWatcher(client, out, time.Hour)
+		installation, err := subject.Wait(ctx, 303)
+
+		Expect(err).To(Equal(context.Canceled))
+		Expect(installation.Status).To(Equal("running"))
+	})
+
+	It("surfaces errors from ops manager", func() {
+		client.GetReturns(nil, errors.New("unreachable"))
+
+		subject := installations.NewWatcher(client, out, time.Millisecond)
+		_, err := subject.Wait(context.Background(), 303)
+
+		Expect(err).To(MatchError("unreachable"))
+	})
+})
@@ -1,12 +1,14 @@
 package manifest
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
 
-		"github.com/pivotal-cloudops/omen/internal/tile"
+	"github.com/pivotal-cloudops/omen/internal/parallel"
+	"github.com/pivotal-cloudops/omen/internal/tile"
 )
 
 type productStatus string
@@ -18,12 +20,12 @@ const (
 
 
 type omClient interface {
-	Get(endpoint string, timeout time.Duration) ([]byte, error)
+	Get(ctx context.Context, endpoint string, timeout time.Duration) ([]byte, error)
 }
 
 type tilesLoader interface {
-	LoadStaged(bool) (tile.Tiles, error)
-	LoadDeployed(bool) (tile.Tiles, error)
+	LoadStaged(context.Context, bool) (tile.Tiles, error)
+	LoadDeployed(context.Context, bool) (tile.Tiles, error)
 }
 
 type Manifest struct {
@@ -41,46 +43,47 @@ type Manifests struct {
 }
 
 type Loader struct {
-	client omClient
-	tl     tilesLoader
+	client      omClient
+	tl          tilesLoader
+	parallelism int
 }
 
-func NewManifestsLoader(omClient omClient, tl tilesLoader) Loader {
-	return Loader{client: omClient, tl: tl}
+func NewManifestsLoader(omClient omClient, tl tilesLoader, parallelism int) Loader {
+	return Loader{client: omClient, tl: tl, parallelism: parallelism}
 }
 
-func (l Loader) LoadAllStaged() (Manifests, error) {
-	return l.loadAll(staged)
+func (l Loader) LoadAllStaged(ctx context.Context) (Manifests, error) {
+	return l.loadAll(ctx, staged)
 }
 
-func (l Loader) LoadAllDeployed() (Manifests, error) {
-	return l.loadAll(deployed)
+func (l Loader) LoadAllDeployed(ctx context.Context) (Manifests, error) {
+	return l.loadAll(ctx, deployed)
 }
 
-func (l Loader) loadAll(status productStatus) (Manifests, error) {
-	tileGuids, err := l.getAllTileGuids(status)
+func (l Loader) loadAll(ctx context.Context, status productStatus) (Manifests, error) {
+	tileGuids, err := l.getAllTileGuids(ctx, status)
 	if err != nil {
 		return Manifests{}, err
 	}
 
-	return l.load(status, tileGuids)
+	return l.load(ctx, status, tileGuids)
 }
 
-func (l Loader) LoadStaged(tileGuids []string) (Manifests, error) {
-	return l.load(staged, tileGuids)
+func (l Loader) LoadStaged(ctx context.Context, tileGuids []string) (Manifests, error) {
+	return l.load(ctx, staged, tileGuids)
 }
 
-func (l Loader) LoadDeployed(tileGuids []string) (Manifests, error)  {
-	return l.load(deployed, tileGuids)
+func (l Loader) LoadDeployed(ctx context.Context, tileGuids []string) (Manifests, error)  {
+	return l.load(ctx, deployed, tileGuids)
 }
 
-func (l Loader) load(status productStatus, tileGuids []string) (Manifests, error) {
-	manifests, err := l.loadManifests(tileGuids, status)
+func (l Loader) load(ctx context.Context, status productStatus, tileGuids []string) (Manifests, error) {
+	manifests, err := l.loadManifests(ctx, tileGuids, status)
 	if err != nil {
 		return Manifests{}, err
 	}
 
-	cloudConfig, err := l.loadCloudConfig(status)
+	cloudConfig, err := l.loadCloudConfig(ctx, status)
 	if err != nil {
 		return Manifests{}, err
 	}
@@ -88,7 +91,7 @@ func (l Loader) load(status productStatus, tileGuids []string) (Manifests, error
 	return Manifests{manifests, cloudConfig}, nil
 }
 
-func (l Loader) getAllTileGuids(status productStatus) ([]string, error) {
+func (l Loader) getAllTileGuids(ctx context.Context, status productStatus) ([]string, error) {
 	var (
 		tiles  tile.Tiles
 		err    error
@@ -96,9 +99,9 @@ func (l Loader) getAllTileGuids(status productStatus) ([]string, error) {
 	)
 
 	if status == deployed {
-		tiles, err = l.tl.LoadDeployed(false)
+		tiles, err = l.tl.LoadDeployed(ctx, false)
 	} else {
-		tiles, err = l.tl.LoadStaged(false)
+		tiles, err = l.tl.LoadStaged(ctx, false)
 	}
 
 	if err != nil {
@@ -112,8 +115,8 @@ func (l Loader) getAllTileGuids(status productStatus) ([]string, error) {
 	return result, err
 }
 
-func (l Loader) loadCloudConfig(status productStatus) (interface{}, error) {
-	response, err := l.client.Get(fmt.Sprintf("/api/v0/%s/cloud_config", status), 10*time.Minute)
+func (l Loader) loadCloudConfig(ctx context.Context, status productStatus) (interface{}, error) {
+	response, err := l.client.Get(ctx, fmt.Sprintf("/api/v0/%s/cloud_config", status), 10*time.Minute)
 	if err != nil {
 		return nil, err
 	}
@@ -129,37 +132,35 @@ func getEndpoint(tileGuid string, status productStatus) string {
 	return fmt.Sprintf("/api/v0/%s/products/%s/manifest", status, tileGuid)
 }
 
-func (l Loader) loadManifests(tileGuids []string, status productStatus) ([]Manifest, error) {
-	var manifests []Manifest
+// loadManifests fetches the manifests of the tiles, up to the loader's
+// parallelism at a time, keeping them in the order of the tile GUIDs.
+func (l Loader) loadManifests(ctx context.Context, tileGuids []string, status productStatus) ([]Manifest, error) {
+	if len(tileGuids) == 0 {
+		return nil, nil
+	}
 
-	for _, t := range tileGuids {
-		data, err := l.client.Get(getEndpoint(t, status), 10*time.Minute)
+	manifests := make([]Manifest, len(tileGuids))
+	err := parallel.Run(ctx, len(tileGuids), l.parallelism, func(ctx context.Context, i int) error {
+		data, err := l.client.Get(ctx, getEndpoint(tileGuids[i], status), 10*time.Minute)
 		if err != nil {
-			return nil, err
+			return err
 		}
 
-		var (
-			temp map[string]Manifest
-			m    Manifest
-		)
-
-		m = Manifest{}
-
 		if status == deployed {
-			err = json.Unmarshal(data, &m)
-			if err != nil {
-				return nil, err
-			}
-		} else {
-			temp = make(map[string]Manifest)
-			err = json.Unmarshal(data, &temp)
-			if err != nil {
-				return nil, err
-			}
-			m = temp["manifest"]
+			return json.Unmarshal(data, &manifests[i])
 		}
 
-		manifests = append(manifests, m)
+		temp := make(map[string]Manifest)
+		err = json.Unmarshal(data, &temp)
+		if err != nil {
+			return err
+		}
+		manifests[i] = temp["manifest"]
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
+
 	return manifests, nil
 }
@@ -1,9 +1,11 @@
 package manifest_test
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/ginkgo/extensions/table"
@@ -33,8 +35,8 @@ var _ = Describe("Manifests loader", func() {
 			},
 		}
 
-		tl := tile.NewTilesLoader(fakeOMClient)
-		loader := manifest.NewManifestsLoader(fakeOMClient, tl)
+		tl := tile.NewTilesLoader(fakeOMClient, 4)
+		loader := manifest.NewManifestsLoader(fakeOMClient, tl, 4)
 
 		var (
 			manifests manifest.Manifests
@@ -42,9 +44,9 @@ var _ = Describe("Manifests loader", func() {
 		)
 
 		if status == "deployed" {
-			manifests, err = loader.LoadAllDeployed()
+			manifests, err = loader.LoadAllDeployed(context.Background())
 		} else {
-			manifests, err = loader.LoadAllStaged()
+			manifests, err = loader.LoadAllStaged(context.Background())
 		}
 
 		Expect(err).ToNot(HaveOccurred())
@@ -90,10 +92,10 @@ var _ = Describe("Manifests loader", func() {
 				},
 			}
 
-			tl := tile.NewTilesLoader(fakeOMClient)
-			loader := manifest.NewManifestsLoader(fakeOMClient, tl)
+			tl := tile.NewTilesLoader(fakeOMClient, 4)
+			loader := manifest.NewManifestsLoader(fakeOMClient, tl, 4)
 
-			manifests, err := loader.LoadStaged([]string{"guid"})
+			manifests, err := loader.LoadStaged(context.Background(), []string{"guid"})
 			Expect(err).ToNot(HaveOccurred())
 			Expect(len(manifests.Data)).To(Equal(1))
 
@@ -120,10 +122,10 @@ var _ = Describe("Manifests loader", func() {
 			},
 		}
 
-		tl := tile.NewTilesLoader(fakeOMClient)
-		loader := manifest.NewManifestsLoader(fakeOMClient, tl)
+		tl := tile.NewTilesLoader(fakeOMClient, 4)
+		loader := manifest.NewManifestsLoader(fakeOMClient, tl, 4)
 
-		_, err := loader.LoadAllDeployed()
+		_, err := loader.LoadAllDeployed(context.Background())
 		Expect(err).To(HaveOccurred())
 		Expect(err.Error()).To(Equal("cloud config error"))
 	})
@@ -141,8 +143,8 @@ var _ = Describe("Manifests loader", func() {
 			},
 		}
 
-		loader := manifest.NewManifestsLoader(fakeOMClient, tl)
-		_, err := loader.LoadAllDeployed()
+		loader := manifest.NewManifestsLoader(fakeOMClient, tl, 4)
+		_, err := loader.LoadAllDeployed(context.Background())
 		Expect(err).To(HaveOccurred())
 	})
 
@@ -159,10 +161,40 @@ var _ = Describe("Manifests loader", func() {
 			},
 		}
 
-		tl := tile.NewTilesLoader(fakeOMClient)
-		loader := manifest.NewManifestsLoader(fakeOMClient, tl)
-		_, err := loader.LoadAllDeployed()
+		tl := tile.NewTilesLoader(fakeOMClient, 4)
+		loader := manifest.NewManifestsLoader(fakeOMClient, tl, 4)
+		_, err := loader.LoadAllDeployed(context.Background())
 		Expect(err).To(HaveOccurred())
 
 	})
+
+	It("keeps the manifests in the order of the tiles when fetching concurrently", func() {
+		guids := []string{"guid-0", "guid-1", "guid-2", "guid-3", "guid-4", "guid-5"}
+
+		fakeOMClient := fakes.FakeOMClient{
+			GetFunc: func(endpoint string) ([]byte, error) {
+				if endpoint == "/api/v0/deployed/cloud_config" {
+					return []byte(`{"cloud_config": {}}`), nil
+				}
+
+				for i, guid := range guids {
+					if endpoint == fmt.Sprintf("/api/v0/deployed/products/%s/manifest", guid) {
+						time.Sleep(time.Duration(len(guids)-i) * time.Millisecond)
+						return []byte(fmt.Sprintf(`{"name": "%s"}`, guid)), nil
+					}
+				}
+				return nil, errors.New(fmt.Sprintf("invalid endpoint %v", endpoint))
+			},
+		}
+
+		loader := manifest.NewManifestsLoader(fakeOMClient, fakes.FakeTilesLoader{}, 3)
+		manifests, err := loader.LoadDeployed(context.Background(), guids)
+		Expect(err).ToNot(HaveOccurred())
+
+		var names []string
+		for _, m := range manifests.Data {
+			names = append(names, m.Name)
+		}
+		Expect(names).To(Equal(guids))
+	})
 })
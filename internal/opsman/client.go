@@ -2,102 +2,194 @@ package opsman
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
+	"io"
 	"io/ioutil"
-	"log"
-	"time"
-
 	"net/http"
+	"strings"
+	"time"
 
-	"github.com/pivotal-cf/om/api"
-	"github.com/pivotal-cf/om/commands"
-	"github.com/pivotal-cf/om/network"
 	"github.com/pkg/errors"
 )
 
 type Client struct {
-	baseUrl      string
-	username     string
-	secret       string
-	clientID     string
-	clientSecret string
+	oauth   *oauthClient
+	retries int
+	backoff time.Duration
 }
 
 var defaultRequestTimeout = 30 * time.Second
 var defaultConnectTimeout = 5 * time.Second
 
-func NewClient(baseUrl string, username, secret, client, clientSecret string) Client {
-	return Client{baseUrl, username, secret, client, clientSecret}
+type ClientOptions struct {
+	TokenCacheDir     string
+	CACert            string
+	SkipSSLValidation bool
+	ClientCert        string
+	ClientKey         string
+	Retries           int
+	RetryBackoff      time.Duration
+	Trace             io.Writer
+}
+
+// Request is a call to the Ops Manager API. The body is sent as JSON unless
+// the header says otherwise.
+type Request struct {
+	Method   string
+	Endpoint string
+	Body     []byte
+	Header   http.Header
+	Timeout  time.Duration
+}
+
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// DecodeJSON decodes the response body into out.
+func (r Response) DecodeJSON(out interface{}) error {
+	return json.Unmarshal(r.Body, out)
+}
+
+// NewClient returns a client that logs in to Ops Manager once and reuses the
+// token for all its requests. When a token cache dir is set, the token is also
+// kept there for later runs. Requests that fail transiently are retried up to
+// Retries times, waiting RetryBackoff before the first retry and doubling that
+// for every following one. When Trace is set, every request is written to it.
+func NewClient(baseUrl string, username, secret, client, clientSecret string, options ClientOptions) (Client, error) {
+	transport, err := newTransport(options)
+	if err != nil {
+		return Client{}, err
+	}
+
+	var roundTripper http.RoundTripper = transport
+	if options.Trace != nil {
+		roundTripper = newTracingTransport(transport, options.Trace)
+	}
+
+	return Client{
+		oauth:   newOAuthClient(baseUrl, username, secret, client, clientSecret, options.TokenCacheDir, roundTripper),
+		retries: options.Retries,
+		backoff: options.RetryBackoff,
+	}, nil
 }
 
-func (c Client) execute(method string, endpoint string, data string, timeout time.Duration) ([]byte, error) {
-	t := timeout
-	if t == 0 {
-		t = defaultRequestTimeout
+func (c Client) client(timeout time.Duration) doer {
+	return retryingClient{client: c.oauth.withTimeout(timeout), retries: c.retries, backoff: c.backoff}
+}
+
+// Send sends the request and reads the whole response. Responses with an error
+// status are returned along with an *Error describing them.
+func (c Client) Send(ctx context.Context, r Request) (Response, error) {
+	timeout := r.Timeout
+	if timeout == 0 {
+		timeout = defaultRequestTimeout
+	}
+
+	var body io.Reader
+	if r.Body != nil {
+		body = bytes.NewReader(r.Body)
 	}
-	oAuthClient, err := network.NewOAuthClient(
-		c.baseUrl,
-		c.username,
-		c.secret,
-		c.clientID,
-		c.clientSecret,
-		true,
-		false,
-		t,
-		defaultConnectTimeout,
-	)
+
+	request, err := http.NewRequest(r.Method, r.Endpoint, body)
 	if err != nil {
-		return []byte(""), err
+		return Response{}, err
+	}
+	request = request.WithContext(ctx)
+
+	for name, values := range r.Header {
+		request.Header[name] = values
+	}
+	if r.Body != nil && request.Header.Get("Content-Type") == "" {
+		request.Header.Set("Content-Type", "application/json")
 	}
 
-	stdout := new(bytes.Buffer)
-	stdoutLogger := log.New(stdout, "", 0)
-	devNull := ioutil.Discard
-	stderrLogger := log.New(devNull, "", 0)
-	requestService := api.New(api.ApiInput{
-		Client: oAuthClient,
-	})
-
-	curlCommand := commands.NewCurl(requestService, stdoutLogger, stderrLogger)
-	switch method {
-	case "GET":
-		err = curlCommand.Execute([]string{"-path", endpoint})
-	case "POST":
-		err = curlCommand.Execute([]string{"-path", endpoint, "-x", "POST", "-d", data})
-	case "DELETE":
-		err = curlCommand.Execute([]string{"-path", endpoint, "-x", "DELETE"})
+	response, err := c.client(timeout).Do(request)
+	if err != nil {
+		if ctx.Err() != nil {
+			err = ctx.Err()
+		}
+		if _, ok := errors.Cause(err).(*Error); ok {
+			return Response{}, err
+		}
+		return Response{}, transportError(r.Method, r.Endpoint, err)
 	}
-	return stdout.Bytes(), err
+	defer response.Body.Close()
+
+	b, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return Response{}, transportError(r.Method, r.Endpoint, err)
+	}
+
+	result := Response{StatusCode: response.StatusCode, Header: response.Header, Body: b}
+	if response.StatusCode >= 400 {
+		return result, statusError(r.Method, r.Endpoint, response.StatusCode, strings.TrimSpace(string(b)))
+	}
+	return result, nil
 }
 
-func (c Client) Get(endpoint string, timeout time.Duration) ([]byte, error) {
-	body, err := c.execute("GET", endpoint, "", timeout)
-	return body, errors.Wrap(err, string(body))
+// GetJSON decodes the response to a GET of the endpoint into out.
+func (c Client) GetJSON(ctx context.Context, endpoint string, out interface{}, timeout time.Duration) error {
+	return c.SendJSON(ctx, "GET", endpoint, nil, out, timeout)
 }
 
-func (c Client) Post(endpoint, data string, timeout time.Duration) ([]byte, error) {
-	body, err := c.execute("POST", endpoint, data, timeout)
-	return body, errors.Wrap(err, string(body))
+// SendJSON sends in, unless nil, encoded as JSON and decodes the response
+// into out, unless nil.
+func (c Client) SendJSON(ctx context.Context, method, endpoint string, in interface{}, out interface{}, timeout time.Duration) error {
+	var body []byte
+	if in != nil {
+		var err error
+		body, err = json.Marshal(in)
+		if err != nil {
+			return err
+		}
+	}
+
+	response, err := c.Send(ctx, Request{Method: method, Endpoint: endpoint, Body: body, Timeout: timeout})
+	if err != nil {
+		return err
+	}
+
+	if out == nil {
+		return nil
+	}
+	return errors.Wrapf(response.DecodeJSON(out), "decoding the response to %s %s", method, endpoint)
 }
 
-func (c Client) Delete(endpoint string, timeout time.Duration) error {
-	_, err := c.execute("DELETE", endpoint, "", timeout)
+func (c Client) execute(ctx context.Context, method string, endpoint string, data string, timeout time.Duration) ([]byte, error) {
+	r := Request{Method: method, Endpoint: endpoint, Timeout: timeout}
+	if data != "" {
+		r.Body = []byte(data)
+	}
+
+	response, err := c.Send(ctx, r)
+	return response.Body, err
+}
+
+func (c Client) Get(ctx context.Context, endpoint string, timeout time.Duration) ([]byte, error) {
+	return c.execute(ctx, "GET", endpoint, "", timeout)
+}
+
+func (c Client) Post(ctx context.Context, endpoint, data string, timeout time.Duration) ([]byte, error) {
+	return c.execute(ctx, "POST", endpoint, data, timeout)
+}
+
+func (c Client) Put(ctx context.Context, endpoint, data string, timeout time.Duration) ([]byte, error) {
+	return c.execute(ctx, "PUT", endpoint, data, timeout)
+}
+
+func (c Client) Patch(ctx context.Context, endpoint, data string, timeout time.Duration) ([]byte, error) {
+	return c.execute(ctx, "PATCH", endpoint, data, timeout)
+}
+
+func (c Client) Delete(ctx context.Context, endpoint string, timeout time.Duration) error {
+	_, err := c.execute(ctx, "DELETE", endpoint, "", timeout)
 	return err
 }
 
 func (c Client) Do(request *http.Request) (*http.Response, error) {
-	client, err := network.NewOAuthClient(
-		c.baseUrl,
-		c.username,
-		c.secret,
-		c.clientID,
-		c.clientSecret,
-		true,
-		false,
-		defaultRequestTimeout,
-		defaultConnectTimeout,
-	)
-	if err != nil {
-		return nil, err
-	}
-	return client.Do(request)
-}
\ No newline at end of file
+	return c.client(defaultRequestTimeout).Do(request)
+}
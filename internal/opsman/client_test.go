@@ -0,0 +1,143 @@
+package opsman_test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pivotal-cloudops/omen/internal/opsman"
+)
+
+var _ = Describe("Client", func() {
+	type received struct {
+		method      string
+		path        string
+		contentType string
+		custom      string
+		body        string
+	}
+
+	var (
+		server   *httptest.Server
+		requests []received
+		client   opsman.Client
+	)
+
+	BeforeEach(func() {
+		requests = nil
+
+		server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/uaa/oauth/token" {
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprint(w, `{"access_token": "token", "token_type": "bearer", "expires_in": 3600}`)
+				return
+			}
+
+			b, _ := ioutil.ReadAll(r.Body)
+			requests = append(requests, received{r.Method, r.URL.Path, r.Header.Get("Content-Type"), r.Header.Get("X-Custom"), string(b)})
+
+			switch r.URL.Path {
+			case "/api/v0/missing":
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, `{"errors": ["not found"]}`)
+			case "/api/v0/slow":
+				time.Sleep(time.Second)
+			default:
+				w.Header().Set("X-Request-Id", "1234")
+				fmt.Fprint(w, `{"name": "cf"}`)
+			}
+		}))
+
+		var err error
+		client, err = opsman.NewClient(server.URL, "admin", "password", "", "", opsman.ClientOptions{CACert: serverCA(server)})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("gets the response body", func() {
+		body, err := client.Get(context.Background(), "/api/v0/staged/products", time.Minute)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(body)).To(Equal(`{"name": "cf"}`))
+		Expect(requests).To(Equal([]received{{method: "GET", path: "/api/v0/staged/products"}}))
+	})
+
+	It("sends request bodies as JSON", func() {
+		_, err := client.Put(context.Background(), "/api/v0/staged/products/cf/properties", `{"properties": {}}`, time.Minute)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = client.Patch(context.Background(), "/api/v0/staged/products/cf", `{"name": "cf"}`, time.Minute)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(requests).To(Equal([]received{
+			{method: "PUT", path: "/api/v0/staged/products/cf/properties", contentType: "application/json", body: `{"properties": {}}`},
+			{method: "PATCH", path: "/api/v0/staged/products/cf", contentType: "application/json", body: `{"name": "cf"}`},
+		}))
+	})
+
+	It("returns an error carrying the status and endpoint of failed requests", func() {
+		body, err := client.Get(context.Background(), "/api/v0/missing", time.Minute)
+
+		Expect(string(body)).To(Equal(`{"errors": ["not found"]}`))
+		Expect(opsman.KindOf(err)).To(Equal(opsman.NotFound))
+		Expect(err.(*opsman.Error).StatusCode).To(Equal(404))
+		Expect(err.(*opsman.Error).Endpoint).To(Equal("/api/v0/missing"))
+	})
+
+	It("sends headers and gives access to the whole response", func() {
+		response, err := client.Send(context.Background(), opsman.Request{
+			Method:   "POST",
+			Endpoint: "/api/v0/installations",
+			Body:     []byte("deploy_products=all"),
+			Header:   http.Header{"Content-Type": {"application/x-www-form-urlencoded"}, "X-Custom": {"yes"}},
+		})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response.StatusCode).To(Equal(http.StatusOK))
+		Expect(response.Header.Get("X-Request-Id")).To(Equal("1234"))
+		Expect(requests[0].contentType).To(Equal("application/x-www-form-urlencoded"))
+		Expect(requests[0].custom).To(Equal("yes"))
+	})
+
+	It("encodes and decodes JSON", func() {
+		var out struct {
+			Name string `json:"name"`
+		}
+
+		err := client.SendJSON(context.Background(), "POST", "/api/v0/things", map[string]string{"name": "p-redis"}, &out, time.Minute)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out.Name).To(Equal("cf"))
+		Expect(requests[0].body).To(MatchJSON(`{"name": "p-redis"}`))
+
+		err = client.GetJSON(context.Background(), "/api/v0/things", &out, time.Minute)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("stops waiting when the context is cancelled", func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		_, err := client.Send(ctx, opsman.Request{Method: "GET", Endpoint: "/api/v0/slow"})
+
+		Expect(opsman.KindOf(err)).To(Equal(opsman.Timeout))
+	})
+
+	It("stops retrying when the context is cancelled", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := client.Get(ctx, "/api/v0/staged/products", time.Minute)
+
+		Expect(opsman.KindOf(err)).To(Equal(opsman.Cancelled))
+		Expect(requests).To(BeEmpty())
+	})
+})
@@ -0,0 +1,155 @@
+package opsman
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+
+	"github.com/pkg/errors"
+)
+
+type ErrorKind int
+
+const (
+	OtherError ErrorKind = iota
+	NotFound
+	Unauthorized
+	Conflict
+	ServerError
+	Timeout
+	Cancelled
+)
+
+func (k ErrorKind) String() string {
+	switch k {
+	case NotFound:
+		return "not found"
+	case Unauthorized:
+		return "unauthorized"
+	case Conflict:
+		return "conflict"
+	case ServerError:
+		return "server error"
+	case Timeout:
+		return "timeout"
+	case Cancelled:
+		return "cancelled"
+	}
+	return "error"
+}
+
+// Error is a failed request to Ops Manager, either answered with an error
+// status or not answered at all.
+type Error struct {
+	Kind       ErrorKind
+	Method     string
+	Endpoint   string
+	StatusCode int
+	Body       string
+	Err        error
+}
+
+func (e *Error) Error() string {
+	if e.Kind == Cancelled {
+		return fmt.Sprintf("%s %s was cancelled", e.Method, e.Endpoint)
+	}
+
+	if e.StatusCode == 0 {
+		return fmt.Sprintf("%s %s failed: %s", e.Method, e.Endpoint, e.Err)
+	}
+
+	msg := fmt.Sprintf("%s %s failed with %d %s", e.Method, e.Endpoint, e.StatusCode, http.StatusText(e.StatusCode))
+	if e.Body != "" {
+		msg += ": " + e.Body
+	}
+	return msg
+}
+
+// Cancelled tells whether the request was cancelled rather than failed.
+func (e *Error) Cancelled() bool {
+	return e.Kind == Cancelled
+}
+
+// ExitCode is the exit status of a command failing with this error.
+func (e *Error) ExitCode() int {
+	switch e.Kind {
+	case NotFound:
+		return 3
+	case Unauthorized:
+		return 4
+	case Conflict:
+		return 5
+	case ServerError:
+		return 6
+	case Timeout:
+		return 7
+	case Cancelled:
+		return 130
+	}
+	return 1
+}
+
+// KindOf returns the kind of a request error, or OtherError for any other error.
+func KindOf(err error) ErrorKind {
+	if e, ok := errors.Cause(err).(*Error); ok {
+		return e.Kind
+	}
+	return OtherError
+}
+
+func statusError(method, endpoint string, statusCode int, body string) *Error {
+	kind := OtherError
+	switch {
+	case statusCode == http.StatusNotFound:
+		kind = NotFound
+	case statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden:
+		kind = Unauthorized
+	case statusCode == http.StatusConflict:
+		kind = Conflict
+	case statusCode >= 500:
+		kind = ServerError
+	}
+
+	return &Error{Kind: kind, Method: method, Endpoint: endpoint, StatusCode: statusCode, Body: body}
+}
+
+func transportError(method, endpoint string, err error) *Error {
+	kind := OtherError
+	if isTimeout(err) {
+		kind = Timeout
+	} else if isCancelled(err) {
+		kind = Cancelled
+	}
+
+	return &Error{Kind: kind, Method: method, Endpoint: endpoint, Err: err}
+}
+
+func isTimeout(err error) bool {
+	err = errors.Cause(err)
+	if urlErr, ok := err.(*url.Error); ok {
+		err = urlErr.Err
+	}
+	netErr, ok := err.(net.Error)
+	return ok && netErr.Timeout()
+}
+
+func isCancelled(err error) bool {
+	err = errors.Cause(err)
+	if urlErr, ok := err.(*url.Error); ok {
+		err = urlErr.Err
+	}
+	return err == context.Canceled
+}
+
+// isConnectionFailure tells whether the request failed before it reached Ops
+// Manager, so that it can safely be sent again whatever its method.
+func isConnectionFailure(err error) bool {
+	err = errors.Cause(err)
+	if urlErr, ok := err.(*url.Error); ok {
+		err = urlErr.Err
+	}
+	opErr, ok := err.(*net.OpError)
+	return ok && opErr.Op == "dial"
+}
@@ -0,0 +1,68 @@
+package opsman
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/url"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	pkgerrors "github.com/pkg/errors"
+)
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+var _ = Describe("Errors", func() {
+	DescribeTable("classifies error statuses",
+		func(statusCode int, kind ErrorKind, exitCode int) {
+			err := statusError("GET", "/api/v0/staged/products", statusCode, "oops")
+
+			Expect(KindOf(pkgerrors.Wrap(err, "loading tiles"))).To(Equal(kind))
+			Expect(err.ExitCode()).To(Equal(exitCode))
+			Expect(err.StatusCode).To(Equal(statusCode))
+			Expect(err.Endpoint).To(Equal("/api/v0/staged/products"))
+		},
+		Entry("not found", 404, NotFound, 3),
+		Entry("unauthorized", 401, Unauthorized, 4),
+		Entry("forbidden", 403, Unauthorized, 4),
+		Entry("conflict", 409, Conflict, 5),
+		Entry("server error", 502, ServerError, 6),
+		Entry("other client errors", 422, OtherError, 1),
+	)
+
+	It("describes the failed request", func() {
+		err := statusError("PUT", "/api/v0/staged/products/cf/properties", 422, `{"errors": ["invalid"]}`)
+
+		Expect(err).To(MatchError(`PUT /api/v0/staged/products/cf/properties failed with 422 Unprocessable Entity: {"errors": ["invalid"]}`))
+	})
+
+	It("classifies timeouts", func() {
+		err := transportError("GET", "/api/v0/staged/products", &url.Error{Op: "Get", URL: "/", Err: timeoutError{}})
+
+		Expect(KindOf(err)).To(Equal(Timeout))
+		Expect(err.ExitCode()).To(Equal(7))
+	})
+
+	It("classifies cancelled requests", func() {
+		err := transportError("GET", "/api/v0/staged/products", &url.Error{Op: "Get", URL: "/", Err: context.Canceled})
+
+		Expect(KindOf(err)).To(Equal(Cancelled))
+		Expect(err.ExitCode()).To(Equal(130))
+		Expect(err).To(MatchError("GET /api/v0/staged/products was cancelled"))
+	})
+
+	It("recognises connection failures", func() {
+		Expect(isConnectionFailure(&url.Error{Err: &net.OpError{Op: "dial", Err: errors.New("refused")}})).To(BeTrue())
+		Expect(isConnectionFailure(&url.Error{Err: &net.OpError{Op: "read", Err: errors.New("reset")}})).To(BeFalse())
+	})
+
+	It("treats any other error as other", func() {
+		Expect(KindOf(errors.New("oops"))).To(Equal(OtherError))
+	})
+})
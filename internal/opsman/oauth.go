@@ -0,0 +1,239 @@
+package opsman
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+// oauthClient authenticates requests to Ops Manager with a UAA token that is
+// fetched once and shared by every request, logging in again only when Ops
+// Manager rejects it. The token is also kept in cacheDir, when set, so that
+// following runs don't have to log in either.
+type oauthClient struct {
+	target       string
+	username     string
+	password     string
+	clientID     string
+	clientSecret string
+	cacheDir     string
+	transport    http.RoundTripper
+
+	mu    sync.Mutex
+	token *oauth2.Token
+}
+
+func newOAuthClient(target, username, password, clientID, clientSecret, cacheDir string, transport http.RoundTripper) *oauthClient {
+	if !strings.Contains(target, "://") {
+		target = "https://" + target
+	}
+
+	return &oauthClient{
+		target:       strings.TrimRight(target, "/"),
+		username:     username,
+		password:     password,
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		cacheDir:     cacheDir,
+		transport:    transport,
+	}
+}
+
+// withTimeout returns a client for requests that must finish within timeout.
+func (o *oauthClient) withTimeout(timeout time.Duration) authenticatedClient {
+	return authenticatedClient{
+		oauth:  o,
+		client: &http.Client{Transport: o.transport, Timeout: timeout},
+	}
+}
+
+type authenticatedClient struct {
+	oauth  *oauthClient
+	client *http.Client
+}
+
+// Do sends the request to Ops Manager with the shared token, fetching a new
+// token and retrying once if the request is rejected as unauthorized.
+func (a authenticatedClient) Do(request *http.Request) (*http.Response, error) {
+	err := a.oauth.resolve(request)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := a.oauth.getToken(request.Context(), a.client, nil)
+	if err != nil {
+		return nil, explainCertificateError(a.oauth.target, err)
+	}
+
+	token.SetAuthHeader(request)
+	response, err := a.client.Do(request)
+	if err != nil || response.StatusCode != http.StatusUnauthorized {
+		return response, explainCertificateError(a.oauth.target, err)
+	}
+
+	if request.Body != nil && request.GetBody == nil {
+		return response, nil
+	}
+	response.Body.Close()
+
+	token, err = a.oauth.getToken(request.Context(), a.client, token)
+	if err != nil {
+		return nil, err
+	}
+
+	if request.GetBody != nil {
+		request.Body, err = request.GetBody()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	token.SetAuthHeader(request)
+	return a.client.Do(request)
+}
+
+// resolve points requests made with a bare path at the Ops Manager target.
+func (o *oauthClient) resolve(request *http.Request) error {
+	if request.URL.Host != "" {
+		return nil
+	}
+
+	target, err := url.Parse(o.target)
+	if err != nil {
+		return err
+	}
+
+	request.URL.Scheme = target.Scheme
+	request.URL.Host = target.Host
+	request.URL.Path = target.Path + request.URL.Path
+	return nil
+}
+
+// getToken returns the shared token, logging in when there is none yet, or
+// when the token is stale and no other request has replaced it already.
+func (o *oauthClient) getToken(ctx context.Context, client *http.Client, stale *oauth2.Token) (*oauth2.Token, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.token != nil && o.token != stale && o.token.Valid() {
+		return o.token, nil
+	}
+
+	if o.token == nil && stale == nil {
+		cached := o.readCachedToken()
+		if cached != nil && cached.Valid() {
+			o.token = cached
+			return o.token, nil
+		}
+	}
+
+	token, err := o.login(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	o.token = token
+	o.writeCachedToken(token)
+	return token, nil
+}
+
+func (o *oauthClient) login(ctx context.Context, client *http.Client) (*oauth2.Token, error) {
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, client)
+	tokenURL := o.target + "/uaa/oauth/token"
+
+	var (
+		token *oauth2.Token
+		err   error
+	)
+	if o.clientID != "" {
+		config := clientcredentials.Config{
+			ClientID:     o.clientID,
+			ClientSecret: o.clientSecret,
+			TokenURL:     tokenURL,
+		}
+		token, err = config.Token(ctx)
+	} else {
+		config := oauth2.Config{
+			ClientID: "opsman",
+			Endpoint: oauth2.Endpoint{TokenURL: tokenURL},
+		}
+		token, err = config.PasswordCredentialsToken(ctx, o.username, o.password)
+	}
+	if err != nil {
+		if retrieveErr, ok := err.(*oauth2.RetrieveError); ok {
+			err = statusError("POST", "/uaa/oauth/token", retrieveErr.Response.StatusCode, strings.TrimSpace(string(retrieveErr.Body)))
+		}
+		return nil, errors.Wrapf(err, "could not log in to %s", o.target)
+	}
+
+	return token, nil
+}
+
+// cacheFile is named after the target and identity the token belongs to, so
+// tokens of different foundations and users don't mix.
+func (o *oauthClient) cacheFile() string {
+	if o.cacheDir == "" {
+		return ""
+	}
+
+	key := sha256.Sum256([]byte(o.target + "\n" + o.username + "\n" + o.clientID))
+	return filepath.Join(o.cacheDir, fmt.Sprintf("%x.json", key[:8]))
+}
+
+func (o *oauthClient) readCachedToken() *oauth2.Token {
+	path := o.cacheFile()
+	if path == "" {
+		return nil
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	var token oauth2.Token
+	err = json.Unmarshal(b, &token)
+	if err != nil {
+		return nil
+	}
+	return &token
+}
+
+// writeCachedToken saves the token readable by the current user only. The
+// cache is an optimisation, so failing to write it is not an error.
+func (o *oauthClient) writeCachedToken(token *oauth2.Token) {
+	path := o.cacheFile()
+	if path == "" {
+		return
+	}
+
+	err := os.MkdirAll(o.cacheDir, 0700)
+	if err != nil {
+		return
+	}
+
+	b, err := json.Marshal(token)
+	if err != nil {
+		return
+	}
+
+	tmp := path + ".tmp"
+	err = ioutil.WriteFile(tmp, b, 0600)
+	if err != nil {
+		return
+	}
+	os.Rename(tmp, path)
+}
@@ -0,0 +1,185 @@
+package opsman_test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pivotal-cloudops/omen/internal/opsman"
+	"github.com/pivotal-cloudops/omen/internal/userio"
+)
+
+var _ = Describe("Authentication", func() {
+	var (
+		server      *httptest.Server
+		mu          sync.Mutex
+		logins      int
+		grantTypes  []string
+		validToken  string
+		bodies      []string
+		cacheDir    string
+		rejectLogin bool
+	)
+
+	BeforeEach(func() {
+		logins = 0
+		grantTypes = nil
+		bodies = nil
+		rejectLogin = false
+
+		var err error
+		cacheDir, err = ioutil.TempDir("", "")
+		Expect(err).NotTo(HaveOccurred())
+
+		server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			if r.URL.Path == "/uaa/oauth/token" && rejectLogin {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusUnauthorized)
+				fmt.Fprint(w, `{"error": "unauthorized", "error_description": "Bad credentials"}`)
+				return
+			}
+
+			if r.URL.Path == "/uaa/oauth/token" {
+				r.ParseForm()
+				logins++
+				grantTypes = append(grantTypes, r.Form.Get("grant_type"))
+				validToken = fmt.Sprintf("token-%d", logins)
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprintf(w, `{"access_token": "%s", "token_type": "bearer", "expires_in": 3600}`, validToken)
+				return
+			}
+
+			if r.Header.Get("Authorization") != "Bearer "+validToken {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			b, _ := ioutil.ReadAll(r.Body)
+			bodies = append(bodies, string(b))
+			fmt.Fprint(w, `{}`)
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+		os.RemoveAll(cacheDir)
+	})
+
+	newClient := func(username, password, clientID, clientSecret string, options opsman.ClientOptions) opsman.Client {
+		options.CACert = serverCA(server)
+		c, err := opsman.NewClient(server.URL, username, password, clientID, clientSecret, options)
+		Expect(err).NotTo(HaveOccurred())
+		return c
+	}
+
+	get := func(c opsman.Client) int {
+		req, err := http.NewRequest("GET", "/api/v0/staged/products", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		resp, err := c.Do(req)
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	It("logs in once for all requests", func() {
+		c := newClient("admin", "password", "", "", opsman.ClientOptions{})
+
+		var wg sync.WaitGroup
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+				Expect(get(c)).To(Equal(http.StatusOK))
+			}()
+		}
+		wg.Wait()
+
+		Expect(logins).To(Equal(1))
+		Expect(grantTypes).To(Equal([]string{"password"}))
+	})
+
+	It("logs in with client credentials when a client ID is given", func() {
+		c := newClient("", "", "client", "client-secret", opsman.ClientOptions{})
+
+		Expect(get(c)).To(Equal(http.StatusOK))
+		Expect(grantTypes).To(Equal([]string{"client_credentials"}))
+	})
+
+	It("logs in again and retries when the token is rejected", func() {
+		c := newClient("admin", "password", "", "", opsman.ClientOptions{})
+		Expect(get(c)).To(Equal(http.StatusOK))
+
+		mu.Lock()
+		validToken = "revoked"
+		mu.Unlock()
+
+		req, err := http.NewRequest("PUT", "/api/v0/staged/products/cf/properties", strings.NewReader(`{"properties": {}}`))
+		Expect(err).NotTo(HaveOccurred())
+
+		resp, err := c.Do(req)
+		Expect(err).NotTo(HaveOccurred())
+		resp.Body.Close()
+
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(logins).To(Equal(2))
+		Expect(bodies).To(Equal([]string{"", `{"properties": {}}`}))
+	})
+
+	It("fails as unauthorized when the credentials are rejected", func() {
+		rejectLogin = true
+		c := newClient("admin", "wrong", "", "", opsman.ClientOptions{})
+
+		_, err := c.Send(context.Background(), opsman.Request{Method: "GET", Endpoint: "/api/v0/staged/products"})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("could not log in to " + server.URL))
+		Expect(opsman.KindOf(err)).To(Equal(opsman.Unauthorized))
+		Expect(userio.ExitCode(err)).To(Equal(4))
+	})
+
+	Context("when caching the token on disk", func() {
+		It("reuses the token in later runs", func() {
+			Expect(get(newClient("admin", "password", "", "", opsman.ClientOptions{TokenCacheDir: cacheDir}))).To(Equal(http.StatusOK))
+			Expect(get(newClient("admin", "password", "", "", opsman.ClientOptions{TokenCacheDir: cacheDir}))).To(Equal(http.StatusOK))
+
+			Expect(logins).To(Equal(1))
+
+			files, err := ioutil.ReadDir(cacheDir)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(files).To(HaveLen(1))
+			Expect(files[0].Mode().Perm()).To(Equal(os.FileMode(0600)))
+		})
+
+		It("keeps tokens of different users apart", func() {
+			Expect(get(newClient("admin", "password", "", "", opsman.ClientOptions{TokenCacheDir: cacheDir}))).To(Equal(http.StatusOK))
+			Expect(get(newClient("other", "password", "", "", opsman.ClientOptions{TokenCacheDir: cacheDir}))).To(Equal(http.StatusOK))
+
+			Expect(logins).To(Equal(2))
+		})
+
+		It("logs in again when the cached token has expired", func() {
+			Expect(get(newClient("admin", "password", "", "", opsman.ClientOptions{TokenCacheDir: cacheDir}))).To(Equal(http.StatusOK))
+
+			files, err := filepath.Glob(filepath.Join(cacheDir, "*.json"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(files).To(HaveLen(1))
+			err = ioutil.WriteFile(files[0], []byte(`{"access_token": "token-1", "token_type": "bearer", "expiry": "2000-01-01T00:00:00Z"}`), 0600)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(get(newClient("admin", "password", "", "", opsman.ClientOptions{TokenCacheDir: cacheDir}))).To(Equal(http.StatusOK))
+			Expect(logins).To(Equal(2))
+		})
+	})
+})
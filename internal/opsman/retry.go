@@ -0,0 +1,77 @@
+package opsman
+
+import (
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type doer interface {
+	Do(*http.Request) (*http.Response, error)
+}
+
+// retryingClient sends requests again, waiting twice as long after every
+// attempt, when they fail in a way that is likely to be transient: GETs that
+// fail with a network error or a server error, and requests of any method that
+// could not connect.
+type retryingClient struct {
+	client  doer
+	retries int
+	backoff time.Duration
+}
+
+func (r retryingClient) Do(request *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		response, err := r.client.Do(request)
+		if attempt >= r.retries || request.Context().Err() != nil || !shouldRetry(request, response, err) {
+			return response, err
+		}
+
+		if response != nil {
+			io.Copy(ioutil.Discard, response.Body)
+			response.Body.Close()
+		}
+
+		if request.GetBody != nil {
+			request.Body, err = request.GetBody()
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		select {
+		case <-time.After(r.backoff << uint(attempt)):
+		case <-request.Context().Done():
+			return nil, request.Context().Err()
+		}
+	}
+}
+
+func shouldRetry(request *http.Request, response *http.Response, err error) bool {
+	if request.Body != nil && request.GetBody == nil {
+		return false
+	}
+
+	if err != nil {
+		if request.Method == "GET" {
+			return isNetworkFailure(err)
+		}
+		return isConnectionFailure(err)
+	}
+
+	return request.Method == "GET" && response.StatusCode >= 500
+}
+
+func isNetworkFailure(err error) bool {
+	err = errors.Cause(err)
+	if urlErr, ok := err.(*url.Error); ok {
+		err = urlErr.Err
+	}
+	_, ok := err.(net.Error)
+	return ok
+}
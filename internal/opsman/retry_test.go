@@ -0,0 +1,92 @@
+package opsman_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pivotal-cloudops/omen/internal/opsman"
+)
+
+var _ = Describe("Retries", func() {
+	var (
+		server   *httptest.Server
+		mu       sync.Mutex
+		attempts int
+		failures int
+		bodies   []string
+		client   opsman.Client
+	)
+
+	BeforeEach(func() {
+		attempts = 0
+		failures = 0
+		bodies = nil
+
+		server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			if r.URL.Path == "/uaa/oauth/token" {
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprint(w, `{"access_token": "token", "token_type": "bearer", "expires_in": 3600}`)
+				return
+			}
+
+			attempts++
+			b, _ := ioutil.ReadAll(r.Body)
+			bodies = append(bodies, string(b))
+			if attempts <= failures {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			fmt.Fprint(w, `{}`)
+		}))
+
+		var err error
+		client, err = opsman.NewClient(server.URL, "admin", "password", "", "",
+			opsman.ClientOptions{CACert: serverCA(server), Retries: 2})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	do := func(method string, body string) int {
+		req, err := http.NewRequest(method, "/api/v0/staged/products", strings.NewReader(body))
+		Expect(err).NotTo(HaveOccurred())
+
+		resp, err := client.Do(req)
+		Expect(err).NotTo(HaveOccurred())
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	It("retries GETs that fail with a server error", func() {
+		failures = 2
+
+		Expect(do("GET", "")).To(Equal(http.StatusOK))
+		Expect(attempts).To(Equal(3))
+	})
+
+	It("gives up after the configured number of retries", func() {
+		failures = 5
+
+		Expect(do("GET", "")).To(Equal(http.StatusServiceUnavailable))
+		Expect(attempts).To(Equal(3))
+	})
+
+	It("does not retry requests that may have changed something", func() {
+		failures = 1
+
+		Expect(do("POST", `{"deploy_products": "all"}`)).To(Equal(http.StatusServiceUnavailable))
+		Expect(attempts).To(Equal(1))
+		Expect(bodies).To(Equal([]string{`{"deploy_products": "all"}`}))
+	})
+})
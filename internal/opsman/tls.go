@@ -0,0 +1,91 @@
+package opsman
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// newTransport returns the transport for all requests to Ops Manager. It
+// verifies the Ops Manager certificate against the system roots and the CA
+// certificate from the options, unless verification is skipped.
+func newTransport(options ClientOptions) (*http.Transport, error) {
+	config := &tls.Config{InsecureSkipVerify: options.SkipSSLValidation}
+
+	if options.CACert != "" {
+		pem, err := readPEM(options.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("could not read the CA certificate: %s", err)
+		}
+
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("the CA certificate %s contains no PEM encoded certificates", describePEM(options.CACert))
+		}
+		config.RootCAs = pool
+	}
+
+	if options.ClientCert != "" || options.ClientKey != "" {
+		if options.ClientCert == "" || options.ClientKey == "" {
+			return nil, fmt.Errorf("mutual TLS needs both a client certificate and a client key")
+		}
+
+		certPEM, err := readPEM(options.ClientCert)
+		if err != nil {
+			return nil, fmt.Errorf("could not read the client certificate: %s", err)
+		}
+
+		keyPEM, err := readPEM(options.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("could not read the client key: %s", err)
+		}
+
+		cert, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			return nil, fmt.Errorf("could not load the client certificate: %s", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return &http.Transport{
+		Proxy:           http.ProxyFromEnvironment,
+		TLSClientConfig: config,
+		DialContext:     (&net.Dialer{Timeout: defaultConnectTimeout}).DialContext,
+	}, nil
+}
+
+// readPEM accepts either PEM content or the path of a PEM file.
+func readPEM(value string) ([]byte, error) {
+	if strings.Contains(value, "-----BEGIN") {
+		return []byte(value), nil
+	}
+	return ioutil.ReadFile(value)
+}
+
+func describePEM(value string) string {
+	if strings.Contains(value, "-----BEGIN") {
+		return "given"
+	}
+	return value
+}
+
+// explainCertificateError points at the TLS options when the Ops Manager
+// certificate could not be verified, and returns other errors unchanged.
+func explainCertificateError(target string, err error) error {
+	if err == nil || !strings.Contains(err.Error(), "x509: ") {
+		return err
+	}
+
+	return errors.Wrapf(err, "could not verify the certificate of %s; "+
+		"pass the CA certificate that signed it with --ca-cert or $OPSMAN_CA_CERT, "+
+		"or use --skip-ssl-validation to connect without verifying it", target)
+}
@@ -0,0 +1,131 @@
+package opsman_test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pivotal-cloudops/omen/internal/opsman"
+)
+
+func serverCA(server *httptest.Server) string {
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+}
+
+var _ = Describe("TLS", func() {
+	var (
+		server *httptest.Server
+		tmpdir string
+	)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/uaa/oauth/token" {
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"access_token": "token", "token_type": "bearer", "expires_in": 3600}`)
+			return
+		}
+		fmt.Fprint(w, `{}`)
+	})
+
+	BeforeEach(func() {
+		var err error
+		tmpdir, err = ioutil.TempDir("", "")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		server.Close()
+		os.RemoveAll(tmpdir)
+	})
+
+	get := func(options opsman.ClientOptions) error {
+		c, err := opsman.NewClient(server.URL, "admin", "password", "", "", options)
+		if err != nil {
+			return err
+		}
+
+		req, err := http.NewRequest("GET", "/api/v0/info", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		resp, err := c.Do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		return nil
+	}
+
+	Context("with a server certificate signed by an unknown CA", func() {
+		BeforeEach(func() {
+			server = httptest.NewTLSServer(handler)
+		})
+
+		It("refuses to connect and explains how to trust the certificate", func() {
+			err := get(opsman.ClientOptions{})
+
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("could not verify the certificate of " + server.URL))
+			Expect(err.Error()).To(ContainSubstring("--ca-cert"))
+			Expect(err.Error()).To(ContainSubstring("--skip-ssl-validation"))
+		})
+
+		It("connects when the CA certificate is given as a file", func() {
+			caFile := filepath.Join(tmpdir, "ca.pem")
+			Expect(ioutil.WriteFile(caFile, []byte(serverCA(server)), 0644)).To(Succeed())
+
+			Expect(get(opsman.ClientOptions{CACert: caFile})).To(Succeed())
+		})
+
+		It("connects when the CA certificate is given as PEM", func() {
+			Expect(get(opsman.ClientOptions{CACert: serverCA(server)})).To(Succeed())
+		})
+
+		It("connects without verifying when asked to skip validation", func() {
+			Expect(get(opsman.ClientOptions{SkipSSLValidation: true})).To(Succeed())
+		})
+
+		It("fails when the CA certificate is not PEM", func() {
+			caFile := filepath.Join(tmpdir, "ca.pem")
+			Expect(ioutil.WriteFile(caFile, []byte("not a certificate"), 0644)).To(Succeed())
+
+			err := get(opsman.ClientOptions{CACert: caFile})
+			Expect(err).To(MatchError("the CA certificate " + caFile + " contains no PEM encoded certificates"))
+		})
+	})
+
+	Context("with a server that requires client certificates", func() {
+		var certFile, keyFile string
+
+		BeforeEach(func() {
+			server = httptest.NewUnstartedServer(handler)
+			server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+			server.StartTLS()
+
+			cert := server.TLS.Certificates[0]
+			key, err := x509.MarshalPKCS8PrivateKey(cert.PrivateKey)
+			Expect(err).NotTo(HaveOccurred())
+
+			certFile = filepath.Join(tmpdir, "client.pem")
+			keyFile = filepath.Join(tmpdir, "client.key")
+			Expect(ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Certificate[0]}), 0644)).To(Succeed())
+			Expect(ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: key}), 0600)).To(Succeed())
+		})
+
+		It("presents the client certificate", func() {
+			Expect(get(opsman.ClientOptions{CACert: serverCA(server), ClientCert: certFile, ClientKey: keyFile})).To(Succeed())
+		})
+
+		It("needs both the client certificate and key", func() {
+			err := get(opsman.ClientOptions{CACert: serverCA(server), ClientCert: certFile})
+			Expect(err).To(MatchError("mutual TLS needs both a client certificate and a client key"))
+		})
+	})
+})
@@ -0,0 +1,136 @@
+package opsman
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pivotal-cloudops/omen/internal/redact"
+)
+
+// maxTracedBody is how much of a redacted body the trace shows.
+const maxTracedBody = 4096
+
+// tracingTransport writes every request, including logins and retries, to out
+// with its status, duration and bodies. Headers are left out, so tokens and
+// basic auth credentials never show up, and secrets in bodies are redacted.
+type tracingTransport struct {
+	next http.RoundTripper
+	out  io.Writer
+	mu   *sync.Mutex
+}
+
+func newTracingTransport(next http.RoundTripper, out io.Writer) tracingTransport {
+	return tracingTransport{next: next, out: out, mu: &sync.Mutex{}}
+}
+
+func (t tracingTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	var requestBody []byte
+	if request.GetBody != nil {
+		body, err := request.GetBody()
+		if err == nil {
+			requestBody, _ = ioutil.ReadAll(body)
+			body.Close()
+		}
+	}
+
+	start := time.Now()
+	response, err := t.next.RoundTrip(request)
+	duration := time.Since(start).Round(time.Millisecond)
+
+	var entry bytes.Buffer
+	target := tracedURL(request.URL)
+	if err != nil {
+		fmt.Fprintf(&entry, "%s %s failed after %s: %s\n", request.Method, target, duration, err)
+	} else {
+		fmt.Fprintf(&entry, "%s %s %s (%s)\n", request.Method, target, response.Status, duration)
+	}
+	writeTracedBody(&entry, "> ", request.Header.Get("Content-Type"), requestBody)
+
+	if response != nil {
+		responseBody, readErr := ioutil.ReadAll(response.Body)
+		response.Body.Close()
+		response.Body = ioutil.NopCloser(bytes.NewReader(responseBody))
+		if readErr != nil {
+			err = readErr
+		}
+		writeTracedBody(&entry, "< ", response.Header.Get("Content-Type"), responseBody)
+	}
+
+	t.mu.Lock()
+	t.out.Write(entry.Bytes())
+	t.mu.Unlock()
+
+	return response, err
+}
+
+func tracedURL(u *url.URL) string {
+	traced := *u
+	traced.User = nil
+	if traced.RawQuery != "" {
+		traced.RawQuery = redactForm(traced.RawQuery)
+	}
+	return traced.String()
+}
+
+func writeTracedBody(w io.Writer, prefix, contentType string, body []byte) {
+	if len(body) == 0 {
+		return
+	}
+
+	traced := redactBody(contentType, body)
+	if len(traced) > maxTracedBody {
+		traced = fmt.Sprintf("%s... (%d more bytes)", traced[:maxTracedBody], len(traced)-maxTracedBody)
+	}
+	fmt.Fprintf(w, "%s%s\n", prefix, traced)
+}
+
+// redactBody redacts JSON and form bodies. Bodies of any other type can't be
+// redacted reliably, so only their size is shown.
+func redactBody(contentType string, body []byte) string {
+	if strings.HasPrefix(contentType, "application/x-www-form-urlencoded") {
+		return redactForm(string(body))
+	}
+
+	var data interface{}
+	if json.Unmarshal(body, &data) != nil {
+		return fmt.Sprintf("(%d bytes of %s)", len(body), contentType)
+	}
+
+	redacted, err := redact.Redact(data)
+	if err != nil {
+		return fmt.Sprintf("(%d bytes of %s)", len(body), contentType)
+	}
+
+	b, err := json.Marshal(redacted)
+	if err != nil {
+		return fmt.Sprintf("(%d bytes of %s)", len(body), contentType)
+	}
+	return string(b)
+}
+
+// redactForm redacts the sensitive values of a form, keeping it in order.
+func redactForm(form string) string {
+	fields := strings.Split(form, "&")
+	for i, field := range fields {
+		kv := strings.SplitN(field, "=", 2)
+		key, err := url.QueryUnescape(kv[0])
+		if err != nil || len(kv) < 2 || !redact.IsSensitiveKey(key) {
+			continue
+		}
+
+		value, err := url.QueryUnescape(kv[1])
+		if err != nil {
+			value = kv[1]
+		}
+		fields[i] = kv[0] + "=" + redact.Value(value)
+	}
+	return strings.Join(fields, "&")
+}
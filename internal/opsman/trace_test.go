@@ -0,0 +1,72 @@
+package opsman_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pivotal-cloudops/omen/internal/opsman"
+)
+
+var _ = Describe("Tracing", func() {
+	var (
+		server *httptest.Server
+		trace  *bytes.Buffer
+		client opsman.Client
+	)
+
+	BeforeEach(func() {
+		server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			switch r.URL.Path {
+			case "/uaa/oauth/token":
+				fmt.Fprint(w, `{"access_token": "very-secret-token", "token_type": "bearer", "expires_in": 3600}`)
+			case "/api/v0/missing":
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, `{"errors": ["not found"]}`)
+			default:
+				fmt.Fprint(w, `{"properties": {".properties.system_domain": {"value": "sys.example.com"}, ".properties.db_password": {"value": {"secret": "hunter2"}}}}`)
+			}
+		}))
+
+		trace = &bytes.Buffer{}
+		var err error
+		client, err = opsman.NewClient(server.URL, "admin", "p4ssw0rd", "", "", opsman.ClientOptions{CACert: serverCA(server), Trace: trace})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("traces every request with its status and bodies", func() {
+		_, err := client.Put(context.Background(), "/api/v0/staged/products/cf/properties", `{"properties": {".properties.system_domain": {"value": "sys.example.com"}}}`, time.Minute)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = client.Get(context.Background(), "/api/v0/missing", time.Minute)
+		Expect(err).To(HaveOccurred())
+
+		Expect(trace.String()).To(ContainSubstring("POST " + server.URL + "/uaa/oauth/token 200 OK"))
+		Expect(trace.String()).To(ContainSubstring("PUT " + server.URL + "/api/v0/staged/products/cf/properties 200 OK"))
+		Expect(trace.String()).To(ContainSubstring(`> {"properties":{".properties.system_domain":{"value":"sys.example.com"}}}`))
+		Expect(trace.String()).To(ContainSubstring("GET " + server.URL + "/api/v0/missing 404 Not Found"))
+		Expect(trace.String()).To(ContainSubstring(`< {"errors":["not found"]}`))
+	})
+
+	It("never shows credentials, tokens or secrets", func() {
+		_, err := client.Get(context.Background(), "/api/v0/staged/products/cf/properties", time.Minute)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(trace.String()).To(ContainSubstring("password=<redacted:"))
+		Expect(trace.String()).To(ContainSubstring("sys.example.com"))
+		Expect(trace.String()).NotTo(ContainSubstring("p4ssw0rd"))
+		Expect(trace.String()).NotTo(ContainSubstring("very-secret-token"))
+		Expect(trace.String()).NotTo(ContainSubstring("hunter2"))
+		Expect(trace.String()).NotTo(ContainSubstring("Bearer"))
+	})
+})
@@ -0,0 +1,119 @@
+package parallel
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// canceller is implemented by errors that tell whether they come from a
+// cancelled request, such as those of Ops Manager requests.
+type canceller interface {
+	Cancelled() bool
+}
+
+// Errors holds the errors of several failed calls, in the order of their
+// indexes. Cause is the error of the call that failed first, which stopped
+// the others.
+type Errors struct {
+	Errs  []error
+	first error
+}
+
+func (e Errors) Error() string {
+	messages := make([]string, 0, len(e.Errs))
+	for _, err := range e.Errs {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Cause returns the error of the call that failed first, so that the exit
+// code is the one of the failure that stopped the run.
+func (e Errors) Cause() error {
+	return e.first
+}
+
+// Run calls fn for every index from 0 to n-1, with at most limit calls running
+// at once. Once a call has failed, or ctx is done, no new calls are started.
+// A failure also cancels the context of the calls still running, so that
+// their requests are aborted. Those calls only fail as a consequence, so their
+// cancellation errors are left out but any other error is kept. The error of a
+// single failed call is returned as is, those of several as Errors.
+func Run(ctx context.Context, n int, limit int, fn func(ctx context.Context, i int) error) error {
+	if limit < 1 {
+		limit = 1
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	errs := make([]error, n)
+	sem := make(chan struct{}, limit)
+
+	for i := 0; i < n; i++ {
+		sem <- struct{}{}
+
+		mu.Lock()
+		stop := firstErr != nil || ctx.Err() != nil
+		mu.Unlock()
+		if stop {
+			<-sem
+			break
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			err := fn(ctx, i)
+			if err == nil {
+				return
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if firstErr == nil {
+				firstErr = err
+				errs[i] = err
+				cancel()
+			} else if !isCancellation(err) {
+				errs[i] = err
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if firstErr == nil {
+		return ctx.Err()
+	}
+
+	var result []error
+	for _, err := range errs {
+		if err != nil {
+			result = append(result, err)
+		}
+	}
+	if len(result) == 1 {
+		return result[0]
+	}
+	return Errors{Errs: result, first: firstErr}
+}
+
+func isCancellation(err error) bool {
+	cause := errors.Cause(err)
+	if c, ok := cause.(canceller); ok {
+		return c.Cancelled()
+	}
+	return cause == context.Canceled
+}
@@ -0,0 +1,138 @@
+package parallel_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pivotal-cloudops/omen/internal/parallel"
+	pkgerrors "github.com/pkg/errors"
+)
+
+type cancelledError struct{}
+
+func (cancelledError) Error() string   { return "request was cancelled" }
+func (cancelledError) Cancelled() bool { return true }
+
+var _ = Describe("Run", func() {
+	It("calls the function for every index", func() {
+		results := make([]int, 10)
+
+		err := parallel.Run(context.Background(), 10, 3, func(ctx context.Context, i int) error {
+			results[i] = i * i
+			return nil
+		})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(results).To(Equal([]int{0, 1, 4, 9, 16, 25, 36, 49, 64, 81}))
+	})
+
+	It("runs at most limit calls at once", func() {
+		var (
+			mu      sync.Mutex
+			running int
+			most    int
+		)
+
+		err := parallel.Run(context.Background(), 20, 4, func(ctx context.Context, i int) error {
+			mu.Lock()
+			running++
+			if running > most {
+				most = running
+			}
+			mu.Unlock()
+
+			time.Sleep(5 * time.Millisecond)
+
+			mu.Lock()
+			running--
+			mu.Unlock()
+			return nil
+		})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(most).To(BeNumerically("<=", 4))
+		Expect(most).To(BeNumerically(">", 1))
+	})
+
+	It("stops starting calls after one has failed", func() {
+		var (
+			mu    sync.Mutex
+			calls int
+		)
+
+		err := parallel.Run(context.Background(), 100, 1, func(ctx context.Context, i int) error {
+			mu.Lock()
+			calls++
+			mu.Unlock()
+
+			if i == 2 {
+				return errors.New("boom")
+			}
+			return nil
+		})
+
+		Expect(err).To(MatchError("boom"))
+		Expect(calls).To(Equal(3))
+	})
+
+	It("cancels the calls still running when one fails", func() {
+		started := make(chan struct{})
+
+		err := parallel.Run(context.Background(), 2, 2, func(ctx context.Context, i int) error {
+			if i == 0 {
+				<-started
+				return errors.New("boom")
+			}
+
+			close(started)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Minute):
+				return errors.New("not cancelled")
+			}
+		})
+
+		Expect(err).To(MatchError("boom"))
+	})
+
+	It("returns every error but cancellations, caused by the first failure", func() {
+		failed := make(chan struct{})
+
+		err := parallel.Run(context.Background(), 3, 3, func(ctx context.Context, i int) error {
+			switch i {
+			case 1:
+				defer close(failed)
+				return errors.New("boom")
+			case 0:
+				<-failed
+				<-ctx.Done()
+				return errors.New("could not decode")
+			}
+			<-failed
+			<-ctx.Done()
+			return cancelledError{}
+		})
+
+		Expect(err).To(MatchError("could not decode; boom"))
+		Expect(pkgerrors.Cause(err)).To(MatchError("boom"))
+	})
+
+	It("starts no calls once the context is done", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		calls := 0
+		err := parallel.Run(ctx, 10, 1, func(ctx context.Context, i int) error {
+			calls++
+			return ctx.Err()
+		})
+
+		Expect(err).To(Equal(context.Canceled))
+		Expect(calls).To(BeZero())
+	})
+})
@@ -0,0 +1,258 @@
+package predeploy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type opsmanClient interface {
+	Get(ctx context.Context, endpoint string, timeout time.Duration) ([]byte, error)
+}
+
+type pendingChanges struct {
+	ProductChanges []productChange `json:"product_changes"`
+}
+
+type productChange struct {
+	GUID               string              `json:"guid"`
+	Action             string              `json:"action"`
+	CompletenessChecks *completenessChecks `json:"completeness_checks,omitempty"`
+}
+
+type completenessChecks struct {
+	ConfigurationComplete       bool `json:"configuration_complete"`
+	StemcellPresent             bool `json:"stemcell_present"`
+	ConfigurablePropertiesValid bool `json:"configurable_properties_valid"`
+}
+
+type preDeployCheckResponse struct {
+	PreDeployCheck preDeployCheck `json:"pre_deploy_check"`
+}
+
+type preDeployCheck struct {
+	Identifier string `json:"identifier"`
+	Complete   bool   `json:"complete"`
+	Network    struct {
+		Assigned bool `json:"assigned"`
+	} `json:"network"`
+	AvailabilityZone struct {
+		Assigned bool `json:"assigned"`
+	} `json:"availability_zone"`
+	Stemcells []struct {
+		Assigned                bool   `json:"assigned"`
+		RequiredStemcellVersion string `json:"required_stemcell_version"`
+		RequiredStemcellOS      string `json:"required_stemcell_os"`
+	} `json:"stemcells"`
+	Properties []struct {
+		Name   string   `json:"name"`
+		Errors []string `json:"errors"`
+	} `json:"properties"`
+	Resources struct {
+		Jobs []struct {
+			Identifier string   `json:"identifier"`
+			Errors     []string `json:"error"`
+		} `json:"jobs"`
+	} `json:"resources"`
+	Verifiers []struct {
+		Type      string   `json:"type"`
+		Errors    []string `json:"errors"`
+		Ignorable bool     `json:"ignorable"`
+	} `json:"verifiers"`
+}
+
+type ProductReport struct {
+	GUID       string
+	Identifier string
+	Errors     []string
+	Warnings   []string
+}
+
+type Report struct {
+	Products []ProductReport
+}
+
+func (r Report) HasErrors() bool {
+	for _, p := range r.Products {
+		if len(p.Errors) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (r Report) HasWarnings() bool {
+	for _, p := range r.Products {
+		if len(p.Warnings) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Warnings lists the warnings of all products, each prefixed with the product.
+func (r Report) Warnings() []string {
+	var warnings []string
+	for _, p := range r.Products {
+		product := p.Identifier
+		if product == "" {
+			product = p.GUID
+		}
+		for _, w := range p.Warnings {
+			warnings = append(warnings, fmt.Sprintf("%s: %s", product, w))
+		}
+	}
+	return warnings
+}
+
+func (r Report) String() string {
+	sb := bytes.Buffer{}
+	for _, p := range r.Products {
+		if len(p.Errors) == 0 && len(p.Warnings) == 0 {
+			continue
+		}
+		if p.Identifier != "" {
+			sb.WriteString(fmt.Sprintf("%s (%s)\n", p.Identifier, p.GUID))
+		} else {
+			sb.WriteString(p.GUID + "\n")
+		}
+		for _, e := range p.Errors {
+			sb.WriteString(fmt.Sprintf("  error:   %s\n", e))
+		}
+		for _, w := range p.Warnings {
+			sb.WriteString(fmt.Sprintf("  warning: %s\n", w))
+		}
+	}
+	return sb.String()
+}
+
+type Checker struct {
+	client opsmanClient
+}
+
+func NewChecker(c opsmanClient) Checker {
+	return Checker{client: c}
+}
+
+func (c Checker) Check(ctx context.Context, tileGuids []string) (Report, error) {
+	changes, err := c.pendingChanges(ctx)
+	if err != nil {
+		return Report{}, err
+	}
+
+	report := Report{}
+	for _, pc := range changes.ProductChanges {
+		if pc.Action == "unchanged" || !isSelected(pc.GUID, tileGuids) {
+			continue
+		}
+
+		pr, err := c.checkProduct(ctx, pc)
+		if err != nil {
+			return Report{}, err
+		}
+		report.Products = append(report.Products, pr)
+	}
+
+	return report, nil
+}
+
+func (c Checker) pendingChanges(ctx context.Context) (pendingChanges, error) {
+	resp, err := c.client.Get(ctx, "/api/v0/staged/pending_changes", 10*time.Minute)
+	if err != nil {
+		return pendingChanges{}, err
+	}
+
+	var changes pendingChanges
+	err = json.Unmarshal(resp, &changes)
+	return changes, err
+}
+
+func (c Checker) checkProduct(ctx context.Context, pc productChange) (ProductReport, error) {
+	pr := ProductReport{GUID: pc.GUID}
+
+	if cc := pc.CompletenessChecks; cc != nil {
+		if !cc.ConfigurationComplete {
+			pr.Errors = append(pr.Errors, "configuration is incomplete")
+		}
+		if !cc.StemcellPresent {
+			pr.Errors = append(pr.Errors, "required stemcell is missing")
+		}
+		if !cc.ConfigurablePropertiesValid {
+			pr.Errors = append(pr.Errors, "configurable properties are invalid")
+		}
+	}
+
+	if pc.Action == "delete" {
+		return pr, nil
+	}
+
+	resp, err := c.client.Get(ctx, preDeployCheckEndpoint(pc.GUID), 10*time.Minute)
+	if err != nil {
+		return ProductReport{}, err
+	}
+
+	var check preDeployCheckResponse
+	err = json.Unmarshal(resp, &check)
+	if err != nil {
+		return ProductReport{}, err
+	}
+
+	pdc := check.PreDeployCheck
+	pr.Identifier = pdc.Identifier
+
+	if !pdc.Network.Assigned {
+		pr.Errors = append(pr.Errors, "network is not assigned")
+	}
+	if !pdc.AvailabilityZone.Assigned {
+		pr.Errors = append(pr.Errors, "availability zone is not assigned")
+	}
+	for _, s := range pdc.Stemcells {
+		if !s.Assigned {
+			pr.Errors = append(pr.Errors, fmt.Sprintf("stemcell %s %s is not assigned", s.RequiredStemcellOS, s.RequiredStemcellVersion))
+		}
+	}
+	for _, p := range pdc.Properties {
+		for _, e := range p.Errors {
+			pr.Errors = append(pr.Errors, fmt.Sprintf("property %s: %s", p.Name, e))
+		}
+	}
+	for _, j := range pdc.Resources.Jobs {
+		for _, e := range j.Errors {
+			pr.Errors = append(pr.Errors, fmt.Sprintf("resource %s: %s", j.Identifier, e))
+		}
+	}
+	for _, v := range pdc.Verifiers {
+		for _, e := range v.Errors {
+			msg := fmt.Sprintf("%s: %s", v.Type, e)
+			if v.Ignorable {
+				pr.Warnings = append(pr.Warnings, msg)
+			} else {
+				pr.Errors = append(pr.Errors, msg)
+			}
+		}
+	}
+
+	return pr, nil
+}
+
+func preDeployCheckEndpoint(guid string) string {
+	if strings.HasPrefix(guid, "p-bosh") {
+		return "/api/v0/staged/director/pre_deploy_check"
+	}
+	return fmt.Sprintf("/api/v0/staged/products/%s/pre_deploy_check", guid)
+}
+
+func isSelected(guid string, tileGuids []string) bool {
+	if len(tileGuids) == 0 {
+		return true
+	}
+	for _, g := range tileGuids {
+		if g == guid {
+			return true
+		}
+	}
+	return false
+}
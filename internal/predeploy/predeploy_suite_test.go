@@ -0,0 +1,13 @@
+package predeploy_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestPredeploy(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Predeploy Suite")
+}
@@ -0,0 +1,78 @@
+package predeploy_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pivotal-cloudops/omen/internal/fakes"
+	"github.com/pivotal-cloudops/omen/internal/predeploy"
+)
+
+var _ = Describe("Checker", func() {
+	var fakeOMClient fakes.FakeOMClient
+
+	BeforeEach(func() {
+		fakeOMClient = fakes.FakeOMClient{
+			GetFunc: func(endpoint string) ([]byte, error) {
+				switch endpoint {
+				case "/api/v0/staged/pending_changes":
+					return ioutil.ReadFile("testdata/pending_changes.json")
+				case "/api/v0/staged/products/cf-0123456789abcdef/pre_deploy_check":
+					return ioutil.ReadFile("testdata/cf_pre_deploy_check.json")
+				case "/api/v0/staged/products/p-redis-0123456789abcdef/pre_deploy_check":
+					return ioutil.ReadFile("testdata/p-redis_pre_deploy_check.json")
+				default:
+					return nil, errors.New(fmt.Sprintf("invalid endpoint %v", endpoint))
+				}
+			},
+		}
+	})
+
+	It("categorizes warnings and errors for every changed product", func() {
+		report, err := predeploy.NewChecker(fakeOMClient).Check(context.Background(), []string{})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(report.Products).To(Equal([]predeploy.ProductReport{
+			{
+				GUID:       "cf-0123456789abcdef",
+				Identifier: "cf",
+				Warnings:   []string{"WildcardDomainVerifier: domain *.apps.example.com does not resolve"},
+			},
+			{
+				GUID:       "p-redis-0123456789abcdef",
+				Identifier: "p-redis",
+				Errors: []string{
+					"configuration is incomplete",
+					"stemcell ubuntu-trusty 3468.46 is not assigned",
+					"property .properties.syslog_address: can't be blank",
+				},
+			},
+		}))
+		Expect(report.HasErrors()).To(BeTrue())
+		Expect(report.HasWarnings()).To(BeTrue())
+		Expect(report.Warnings()).To(Equal([]string{"cf: WildcardDomainVerifier: domain *.apps.example.com does not resolve"}))
+	})
+
+	It("only checks the selected products", func() {
+		report, err := predeploy.NewChecker(fakeOMClient).Check(context.Background(), []string{"cf-0123456789abcdef"})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(report.Products).To(HaveLen(1))
+		Expect(report.HasErrors()).To(BeFalse())
+		Expect(report.String()).To(Equal("cf (cf-0123456789abcdef)\n" +
+			"  warning: WildcardDomainVerifier: domain *.apps.example.com does not resolve\n"))
+	})
+
+	It("fails when pending changes cannot be fetched", func() {
+		fakeOMClient.GetFunc = func(endpoint string) ([]byte, error) {
+			return nil, errors.New("oops")
+		}
+
+		_, err := predeploy.NewChecker(fakeOMClient).Check(context.Background(), []string{})
+		Expect(err).To(MatchError("oops"))
+	})
+})
@@ -0,0 +1,175 @@
+package profiles
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	AuthUser   = "user"
+	AuthClient = "client"
+)
+
+// Profile is how to connect to one foundation. Secrets are never kept in the
+// profile itself, only where to read them from.
+type Profile struct {
+	Target            string `yaml:"target"`
+	AuthType          string `yaml:"auth-type"`
+	Username          string `yaml:"username,omitempty"`
+	ClientID          string `yaml:"client-id,omitempty"`
+	CACert            string `yaml:"ca-cert,omitempty"`
+	SkipSSLValidation bool   `yaml:"skip-ssl-validation,omitempty"`
+	CredentialSource  string `yaml:"credential-source,omitempty"`
+}
+
+// Config is the file of named profiles, ~/.omen/config.yml by default.
+type Config struct {
+	Profiles map[string]Profile `yaml:"profiles"`
+}
+
+func DefaultPath() string {
+	return filepath.Join(os.Getenv("HOME"), ".omen", "config.yml")
+}
+
+// Load reads the config at path, returning an empty config if there is none.
+func Load(path string) (Config, error) {
+	config := Config{Profiles: map[string]Profile{}}
+
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return config, nil
+	}
+	if err != nil {
+		return Config{}, err
+	}
+
+	err = yaml.Unmarshal(b, &config)
+	if err != nil {
+		return Config{}, fmt.Errorf("could not read %s: %s", path, err)
+	}
+	if config.Profiles == nil {
+		config.Profiles = map[string]Profile{}
+	}
+	return config, nil
+}
+
+// Save writes the config readable by the current user only.
+func (c Config) Save(path string) error {
+	err := os.MkdirAll(filepath.Dir(path), 0700)
+	if err != nil {
+		return err
+	}
+
+	b, err := yaml.Marshal(c)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, b, 0600)
+}
+
+func (c Config) Names() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (c Config) Get(name string) (Profile, error) {
+	p, ok := c.Profiles[name]
+	if !ok {
+		return Profile{}, fmt.Errorf("profile %s not found", name)
+	}
+	return p, nil
+}
+
+func (c Config) Add(name string, p Profile) error {
+	if _, ok := c.Profiles[name]; ok {
+		return fmt.Errorf("profile %s already exists", name)
+	}
+
+	err := p.Validate()
+	if err != nil {
+		return err
+	}
+
+	c.Profiles[name] = p
+	return nil
+}
+
+func (c Config) Remove(name string) error {
+	if _, ok := c.Profiles[name]; !ok {
+		return fmt.Errorf("profile %s not found", name)
+	}
+	delete(c.Profiles, name)
+	return nil
+}
+
+func (p Profile) Validate() error {
+	if p.Target == "" {
+		return fmt.Errorf("a profile needs a target")
+	}
+
+	switch p.AuthType {
+	case AuthUser:
+		if p.Username == "" {
+			return fmt.Errorf("a profile with user authentication needs a username")
+		}
+	case AuthClient:
+		if p.ClientID == "" {
+			return fmt.Errorf("a profile with client authentication needs a client ID")
+		}
+	default:
+		return fmt.Errorf("invalid auth type %q, expected %s or %s", p.AuthType, AuthUser, AuthClient)
+	}
+
+	if p.CredentialSource != "" {
+		_, _, err := parseCredentialSource(p.CredentialSource)
+		return err
+	}
+	return nil
+}
+
+// Secret reads the password or client secret from the credential source:
+// env:NAME for an environment variable or file:PATH for the content of a file.
+// It returns an empty secret when the profile has no credential source.
+func (p Profile) Secret() (string, error) {
+	if p.CredentialSource == "" {
+		return "", nil
+	}
+
+	kind, value, err := parseCredentialSource(p.CredentialSource)
+	if err != nil {
+		return "", err
+	}
+
+	if kind == "env" {
+		secret := os.Getenv(value)
+		if secret == "" {
+			return "", fmt.Errorf("the credential source environment variable $%s is not set", value)
+		}
+		return secret, nil
+	}
+
+	b, err := ioutil.ReadFile(value)
+	if err != nil {
+		return "", fmt.Errorf("could not read the credential source: %s", err)
+	}
+	return strings.TrimSpace(string(b)), nil
+}
+
+func parseCredentialSource(source string) (string, string, error) {
+	parts := strings.SplitN(source, ":", 2)
+	if len(parts) != 2 || parts[1] == "" || (parts[0] != "env" && parts[0] != "file") {
+		return "", "", fmt.Errorf("invalid credential source %q, expected env:NAME or file:PATH", source)
+	}
+	return parts[0], parts[1], nil
+}
@@ -0,0 +1,112 @@
+package profiles_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pivotal-cloudops/omen/internal/profiles"
+)
+
+var _ = Describe("Profiles", func() {
+	var (
+		tmpdir string
+		path   string
+	)
+
+	prodEast := profiles.Profile{
+		Target:           "https://opsman.prod-east.example.com",
+		AuthType:         profiles.AuthUser,
+		Username:         "admin",
+		CACert:           "/etc/omen/prod-ca.pem",
+		CredentialSource: "env:PROD_EAST_PASSWORD",
+	}
+
+	BeforeEach(func() {
+		var err error
+		tmpdir, err = ioutil.TempDir("", "")
+		Expect(err).NotTo(HaveOccurred())
+		path = filepath.Join(tmpdir, ".omen", "config.yml")
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tmpdir)
+	})
+
+	It("starts with no profiles when there is no config file", func() {
+		config, err := profiles.Load(path)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(config.Names()).To(BeEmpty())
+	})
+
+	It("saves profiles readable by the current user only", func() {
+		config, err := profiles.Load(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(config.Add("prod-east", prodEast)).To(Succeed())
+		Expect(config.Add("sandbox", profiles.Profile{Target: "opsman.sandbox", AuthType: profiles.AuthClient, ClientID: "omen"})).To(Succeed())
+
+		Expect(config.Save(path)).To(Succeed())
+
+		info, err := os.Stat(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Mode().Perm()).To(Equal(os.FileMode(0600)))
+
+		config, err = profiles.Load(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(config.Names()).To(Equal([]string{"prod-east", "sandbox"}))
+		Expect(config.Get("prod-east")).To(Equal(prodEast))
+	})
+
+	It("refuses to add a profile twice or remove a missing one", func() {
+		config, _ := profiles.Load(path)
+		Expect(config.Add("prod-east", prodEast)).To(Succeed())
+
+		Expect(config.Add("prod-east", prodEast)).To(MatchError("profile prod-east already exists"))
+		Expect(config.Remove("prod-west")).To(MatchError("profile prod-west not found"))
+		Expect(config.Remove("prod-east")).To(Succeed())
+
+		_, err := config.Get("prod-east")
+		Expect(err).To(MatchError("profile prod-east not found"))
+	})
+
+	It("validates profiles", func() {
+		config, _ := profiles.Load(path)
+
+		Expect(config.Add("a", profiles.Profile{AuthType: profiles.AuthUser, Username: "admin"})).To(MatchError("a profile needs a target"))
+		Expect(config.Add("a", profiles.Profile{Target: "opsman", AuthType: "saml"})).To(MatchError(`invalid auth type "saml", expected user or client`))
+		Expect(config.Add("a", profiles.Profile{Target: "opsman", AuthType: profiles.AuthClient})).To(MatchError("a profile with client authentication needs a client ID"))
+		Expect(config.Add("a", profiles.Profile{Target: "opsman", AuthType: profiles.AuthUser, Username: "admin", CredentialSource: "hunter2"})).To(
+			MatchError(`invalid credential source "hunter2", expected env:NAME or file:PATH`))
+	})
+
+	Describe("Secret", func() {
+		It("reads the secret from an environment variable", func() {
+			os.Setenv("PROD_EAST_PASSWORD", "s3cret")
+			defer os.Unsetenv("PROD_EAST_PASSWORD")
+
+			Expect(prodEast.Secret()).To(Equal("s3cret"))
+		})
+
+		It("fails when the environment variable is not set", func() {
+			_, err := prodEast.Secret()
+
+			Expect(err).To(MatchError("the credential source environment variable $PROD_EAST_PASSWORD is not set"))
+		})
+
+		It("reads the secret from a file", func() {
+			secretFile := filepath.Join(tmpdir, "secret")
+			Expect(ioutil.WriteFile(secretFile, []byte("s3cret\n"), 0600)).To(Succeed())
+
+			p := profiles.Profile{CredentialSource: "file:" + secretFile}
+
+			Expect(p.Secret()).To(Equal("s3cret"))
+		})
+
+		It("has no secret without a credential source", func() {
+			Expect(profiles.Profile{}.Secret()).To(BeEmpty())
+		})
+	})
+})
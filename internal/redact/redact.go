@@ -0,0 +1,146 @@
+package redact
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var sensitiveWords = map[string]bool{
+	"password": true,
+	"passwd":   true,
+	"secret":   true,
+	"key":      true,
+	"token":    true,
+}
+
+var wordSeparator = regexp.MustCompile(`[^a-z0-9]+`)
+
+// camelCase and acronym boundaries, e.g. adminPassword and APIKey, which are
+// marked as word separators before keys are lowercased.
+var (
+	camelBoundary   = regexp.MustCompile(`([a-z0-9])([A-Z])`)
+	acronymBoundary = regexp.MustCompile(`([A-Z]+)([A-Z][a-z])`)
+)
+
+var interpolation = regexp.MustCompile(`\(\([^()]+\)\)`)
+
+// hashKey keys the hashes of secrets with a random key per run, so that a
+// short hash can't be used to confirm a guessed password offline. Hashes are
+// therefore only comparable within a run.
+var hashKey = newHashKey()
+
+const masked = "<redacted>"
+
+// Redact returns a copy of object, as its JSON representation, with secrets
+// replaced by a short hash so that changed secrets still show up in a diff of
+// the same run without revealing their values.
+func Redact(object interface{}) (interface{}, error) {
+	return redactObject(object, Value)
+}
+
+// Mask returns a copy of object, as its JSON representation, with secrets
+// replaced by a fixed marker, for output that is compared across runs.
+func Mask(object interface{}) (interface{}, error) {
+	return redactObject(object, func(string) string { return masked })
+}
+
+func redactObject(object interface{}, replace func(string) string) (interface{}, error) {
+	j, err := json.Marshal(object)
+	if err != nil {
+		return nil, err
+	}
+
+	var data interface{}
+	err = json.Unmarshal(j, &data)
+	if err != nil {
+		return nil, err
+	}
+
+	variables := map[string]bool{}
+	collectVariables(data, variables)
+
+	return redact(data, false, variables, replace), nil
+}
+
+// Value is the redacted form of a secret: a short hash that is the same for
+// the same secret within a run.
+func Value(s string) string {
+	mac := hmac.New(sha256.New, hashKey)
+	mac.Write([]byte(s))
+	return fmt.Sprintf("<redacted:%s>", hex.EncodeToString(mac.Sum(nil))[:8])
+}
+
+func newHashKey() []byte {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		panic(fmt.Sprintf("could not generate the redaction key: %s", err))
+	}
+	return key
+}
+
+func IsSensitiveKey(key string) bool {
+	key = camelBoundary.ReplaceAllString(key, "${1}_${2}")
+	key = acronymBoundary.ReplaceAllString(key, "${1}_${2}")
+	for _, w := range wordSeparator.Split(strings.ToLower(key), -1) {
+		if sensitiveWords[strings.TrimSuffix(w, "s")] {
+			return true
+		}
+	}
+	return false
+}
+
+func redact(data interface{}, sensitive bool, variables map[string]bool, replace func(string) string) interface{} {
+	switch d := data.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(d))
+		for k, v := range d {
+			if k == "name" {
+				result[k] = redact(v, false, variables, replace)
+				continue
+			}
+			result[k] = redact(v, sensitive || IsSensitiveKey(k) || variables[k], variables, replace)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(d))
+		for i, v := range d {
+			result[i] = redact(v, sensitive, variables, replace)
+		}
+		return result
+	case string:
+		if d != "" && (sensitive || strings.Contains(d, "-----BEGIN") || interpolation.MatchString(d)) {
+			return replace(d)
+		}
+		return d
+	default:
+		return d
+	}
+}
+
+func collectVariables(data interface{}, variables map[string]bool) {
+	switch d := data.(type) {
+	case map[string]interface{}:
+		for k, v := range d {
+			if vars, ok := v.([]interface{}); ok && k == "variables" {
+				for _, variable := range vars {
+					if m, ok := variable.(map[string]interface{}); ok {
+						if name, ok := m["name"].(string); ok {
+							variables[name] = true
+						}
+					}
+				}
+			}
+			collectVariables(v, variables)
+		}
+	case []interface{}:
+		for _, v := range d {
+			collectVariables(v, variables)
+		}
+	}
+}
@@ -0,0 +1,314 @@
+package risk
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pivotal-cloudops/omen/internal/diff"
+)
+
+type Level int
+
+const (
+	Low Level = iota
+	Medium
+	High
+)
+
+func (l Level) String() string {
+	switch l {
+	case High:
+		return "HIGH"
+	case Medium:
+		return "MEDIUM"
+	default:
+		return "LOW"
+	}
+}
+
+type Category string
+
+const (
+	ReleaseBump       Category = "release bump"
+	StemcellChange    Category = "stemcell change"
+	ScaleUp           Category = "scale up"
+	ScaleDown         Category = "scale down"
+	VMTypeChange      Category = "vm type change"
+	DiskGrow          Category = "persistent disk grow"
+	DiskShrink        Category = "persistent disk shrink"
+	DiskChange        Category = "persistent disk change"
+	PlacementChange   Category = "az/network change"
+	PropertiesChange  Category = "properties change"
+	CloudConfigChange Category = "cloud config change"
+	OtherChange       Category = "other change"
+)
+
+var levels = map[Category]Level{
+	ReleaseBump:       Medium,
+	StemcellChange:    Medium,
+	ScaleUp:           Low,
+	ScaleDown:         High,
+	VMTypeChange:      Medium,
+	DiskGrow:          Medium,
+	DiskShrink:        High,
+	DiskChange:        Medium,
+	PlacementChange:   Medium,
+	PropertiesChange:  Low,
+	CloudConfigChange: Medium,
+	OtherChange:       Low,
+}
+
+type Finding struct {
+	Product  string
+	Category Category
+	Level    Level
+	Detail   string
+}
+
+type ProductRisk struct {
+	Product  string
+	Level    Level
+	Findings []Finding
+}
+
+// Assess classifies the changes between the deployed and staged manifests and
+// rates every product by its riskiest change.
+func Assess(deployed interface{}, staged interface{}, ignore diff.IgnoreRules) ([]ProductRisk, error) {
+	changes, _, err := diff.Structured(deployed, staged, ignore)
+	if err != nil {
+		return nil, err
+	}
+
+	oldDisks, err := diskSizes(deployed)
+	if err != nil {
+		return nil, err
+	}
+
+	newDisks, err := diskSizes(staged)
+	if err != nil {
+		return nil, err
+	}
+
+	byProduct := map[string]*ProductRisk{}
+	seen := map[Finding]bool{}
+	for _, c := range changes {
+		f := classify(c, oldDisks, newDisks)
+		if seen[f] {
+			continue
+		}
+		seen[f] = true
+
+		p, ok := byProduct[f.Product]
+		if !ok {
+			p = &ProductRisk{Product: f.Product}
+			byProduct[f.Product] = p
+		}
+		p.Findings = append(p.Findings, f)
+		if f.Level > p.Level {
+			p.Level = f.Level
+		}
+	}
+
+	result := make([]ProductRisk, 0, len(byProduct))
+	for _, p := range byProduct {
+		result = append(result, *p)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Product < result[j].Product
+	})
+
+	return result, nil
+}
+
+func classify(c diff.Change, oldDisks map[string]float64, newDisks map[string]float64) Finding {
+	f := Finding{Product: c.Product}
+	segments := diff.SplitPath(c.Path)
+
+	switch {
+	case segments[0] == "cloud_config":
+		f.Category = CloudConfigChange
+	case len(segments) < 2:
+		f.Category = OtherChange
+	case strings.HasPrefix(segments[1], "releases["):
+		f.Category = ReleaseBump
+		if last(segments) == "version" {
+			f.Detail = diff.IdentityOf(segments[1]) + " " + transition(c)
+		}
+	case strings.HasPrefix(segments[1], "stemcells["):
+		f.Category = StemcellChange
+		if last(segments) == "version" {
+			f.Detail = transition(c)
+		}
+	case segments[1] == "properties":
+		f.Category = PropertiesChange
+	case c.InstanceGroup != "" && len(segments) > 2:
+		classifyInstanceGroup(&f, c, segments[2], oldDisks, newDisks)
+	default:
+		f.Category = OtherChange
+	}
+
+	f.Level = levels[f.Category]
+	return f
+}
+
+func classifyInstanceGroup(f *Finding, c diff.Change, field string, oldDisks map[string]float64, newDisks map[string]float64) {
+	switch {
+	case field == "instances":
+		f.Category = ScaleUp
+		if number(c.NewValue) < number(c.OldValue) {
+			f.Category = ScaleDown
+		}
+		f.Detail = c.InstanceGroup + " " + transition(c)
+	case field == "vm_type" || field == "vm_extensions":
+		f.Category = VMTypeChange
+		f.Detail = c.InstanceGroup
+	case field == "persistent_disk":
+		f.Category = DiskGrow
+		if number(c.NewValue) < number(c.OldValue) {
+			f.Category = DiskShrink
+		}
+		f.Detail = c.InstanceGroup + " " + transition(c)
+	case field == "persistent_disk_type":
+		f.Category = DiskChange
+		oldSize, oldOk := oldDisks[fmt.Sprint(c.OldValue)]
+		newSize, newOk := newDisks[fmt.Sprint(c.NewValue)]
+		if oldOk && newOk {
+			f.Category = DiskGrow
+			if newSize < oldSize {
+				f.Category = DiskShrink
+			}
+		}
+		f.Detail = c.InstanceGroup + " " + transition(c)
+	case field == "azs" || strings.HasPrefix(field, "networks"):
+		f.Category = PlacementChange
+		f.Detail = c.InstanceGroup
+	case field == "stemcell":
+		f.Category = StemcellChange
+		f.Detail = c.InstanceGroup
+	case field == "properties" || strings.HasPrefix(field, "jobs["):
+		f.Category = PropertiesChange
+	default:
+		f.Category = OtherChange
+	}
+}
+
+// Table renders one row per product, riskiest changes first, followed by a
+// warning for every high risk change.
+func Table(products []ProductRisk) string {
+	if len(products) == 0 {
+		return ""
+	}
+
+	buf := bytes.Buffer{}
+	w := tabwriter.NewWriter(&buf, 0, 4, 2, ' ', 0)
+	fmt.Fprint(w, "Product\tRisk\tChanges\n-------\t----\t-------\n")
+
+	var warnings []string
+	for _, p := range products {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", p.Product, p.Level, describe(p.Findings))
+
+		for _, f := range p.Findings {
+			if f.Level == High {
+				warnings = append(warnings, fmt.Sprintf("WARNING: %s in %s: %s\n", f.Category, p.Product, f.Detail))
+			}
+		}
+	}
+	w.Flush()
+
+	if len(warnings) > 0 {
+		buf.WriteString("\n")
+		buf.WriteString(strings.Join(warnings, ""))
+	}
+
+	return buf.String()
+}
+
+func describe(findings []Finding) string {
+	var categories []Category
+	details := map[Category][]string{}
+	for _, f := range findings {
+		if _, ok := details[f.Category]; !ok {
+			categories = append(categories, f.Category)
+			details[f.Category] = nil
+		}
+		if f.Detail != "" {
+			details[f.Category] = appendUnique(details[f.Category], f.Detail)
+		}
+	}
+
+	sort.SliceStable(categories, func(i, j int) bool {
+		if levels[categories[i]] != levels[categories[j]] {
+			return levels[categories[i]] > levels[categories[j]]
+		}
+		return categories[i] < categories[j]
+	})
+
+	parts := make([]string, 0, len(categories))
+	for _, c := range categories {
+		if len(details[c]) > 0 {
+			parts = append(parts, fmt.Sprintf("%s (%s)", c, strings.Join(details[c], ", ")))
+		} else {
+			parts = append(parts, string(c))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+func transition(c diff.Change) string {
+	switch c.Kind {
+	case diff.Added:
+		return fmt.Sprintf("%v added", c.NewValue)
+	case diff.Removed:
+		return fmt.Sprintf("%v removed", c.OldValue)
+	}
+	return fmt.Sprintf("%v -> %v", c.OldValue, c.NewValue)
+}
+
+func number(v interface{}) float64 {
+	n, _ := v.(float64)
+	return n
+}
+
+func last(segments []string) string {
+	return segments[len(segments)-1]
+}
+
+func appendUnique(values []string, v string) []string {
+	for _, existing := range values {
+		if existing == v {
+			return values
+		}
+	}
+	return append(values, v)
+}
+
+// diskSizes returns the sizes of the disk types in the cloud config.
+func diskSizes(manifests interface{}) (map[string]float64, error) {
+	b, err := json.Marshal(manifests)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc map[string]interface{}
+	err = json.Unmarshal(b, &doc)
+	if err != nil {
+		return nil, err
+	}
+
+	sizes := map[string]float64{}
+	cc, _ := doc["cloud_config"].(map[string]interface{})
+	diskTypes, _ := cc["disk_types"].([]interface{})
+	for _, d := range diskTypes {
+		diskType, _ := d.(map[string]interface{})
+		name, ok := diskType["name"].(string)
+		if !ok {
+			continue
+		}
+		sizes[name] = number(diskType["disk_size"])
+	}
+	return sizes, nil
+}
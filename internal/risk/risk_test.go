@@ -0,0 +1,127 @@
+package risk_test
+
+import (
+	"encoding/json"
+
+	"github.com/pivotal-cloudops/omen/internal/diff"
+	"github.com/pivotal-cloudops/omen/internal/risk"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Assess", func() {
+	var deployed, staged interface{}
+
+	BeforeEach(func() {
+		Expect(json.Unmarshal([]byte(RISK_DEPLOYED), &deployed)).To(Succeed())
+		Expect(json.Unmarshal([]byte(RISK_STAGED), &staged)).To(Succeed())
+	})
+
+	It("classifies the changes of every product", func() {
+		products, err := risk.Assess(deployed, staged, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(products).To(Equal([]risk.ProductRisk{
+			{
+				Product: "cf-1234",
+				Level:   risk.High,
+				Findings: []risk.Finding{
+					{Product: "cf-1234", Category: risk.ScaleDown, Level: risk.High, Detail: "router 3 -> 2"},
+					{Product: "cf-1234", Category: risk.PropertiesChange, Level: risk.Low},
+					{Product: "cf-1234", Category: risk.DiskShrink, Level: risk.High, Detail: "uaa large -> small"},
+					{Product: "cf-1234", Category: risk.ReleaseBump, Level: risk.Medium, Detail: "capi 1.2.0 -> 1.3.0"},
+				},
+			},
+			{
+				Product: "cloud_config",
+				Level:   risk.Medium,
+				Findings: []risk.Finding{
+					{Product: "cloud_config", Category: risk.CloudConfigChange, Level: risk.Medium},
+				},
+			},
+			{
+				Product: "p-redis-5678",
+				Level:   risk.Medium,
+				Findings: []risk.Finding{
+					{Product: "p-redis-5678", Category: risk.PlacementChange, Level: risk.Medium, Detail: "redis"},
+					{Product: "p-redis-5678", Category: risk.ScaleUp, Level: risk.Low, Detail: "redis 1 -> 2"},
+					{Product: "p-redis-5678", Category: risk.StemcellChange, Level: risk.Medium, Detail: "170.1 -> 170.2"},
+				},
+			},
+		}))
+	})
+
+	It("leaves out ignored changes", func() {
+		products, err := risk.Assess(deployed, staged, diff.IgnoreRules{{Pattern: "cloud_config.**"}, {Product: "cf", Pattern: "**"}})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(products).To(HaveLen(1))
+		Expect(products[0].Product).To(Equal("p-redis-5678"))
+	})
+
+	It("renders a summary table with warnings for high risk changes", func() {
+		products, err := risk.Assess(deployed, staged, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(risk.Table(products)).To(Equal(`Product       Risk    Changes
+-------       ----    -------
+cf-1234       HIGH    persistent disk shrink (uaa large -> small), scale down (router 3 -> 2), release bump (capi 1.2.0 -> 1.3.0), properties change
+cloud_config  MEDIUM  cloud config change
+p-redis-5678  MEDIUM  az/network change (redis), stemcell change (170.1 -> 170.2), scale up (redis 1 -> 2)
+
+WARNING: scale down in cf-1234: router 3 -> 2
+WARNING: persistent disk shrink in cf-1234: uaa large -> small
+`))
+	})
+
+	It("renders nothing without changes", func() {
+		products, err := risk.Assess(deployed, deployed, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(risk.Table(products)).To(BeEmpty())
+	})
+})
+
+const RISK_DEPLOYED = `{
+  "manifests": [
+    {
+      "name": "cf-1234",
+      "releases": [{"name": "capi", "version": "1.2.0"}],
+      "instance_groups": [
+        {"name": "router", "instances": 3, "jobs": [{"name": "gorouter", "properties": {"port": 80}}]},
+        {"name": "uaa", "instances": 1, "persistent_disk_type": "large"}
+      ]
+    },
+    {
+      "name": "p-redis-5678",
+      "stemcells": [{"alias": "default", "version": "170.1"}],
+      "instance_groups": [{"name": "redis", "instances": 1, "azs": ["z1"]}]
+    }
+  ],
+  "cloud_config": {
+    "disk_types": [{"name": "large", "disk_size": 10240}, {"name": "small", "disk_size": 5120}],
+    "vm_types": [{"name": "medium", "cpu": 2}]
+  }
+}`
+
+const RISK_STAGED = `{
+  "manifests": [
+    {
+      "name": "cf-1234",
+      "releases": [{"name": "capi", "version": "1.3.0"}],
+      "instance_groups": [
+        {"name": "router", "instances": 2, "jobs": [{"name": "gorouter", "properties": {"port": 8080}}]},
+        {"name": "uaa", "instances": 1, "persistent_disk_type": "small"}
+      ]
+    },
+    {
+      "name": "p-redis-5678",
+      "stemcells": [{"alias": "default", "version": "170.2"}],
+      "instance_groups": [{"name": "redis", "instances": 2, "azs": ["z1", "z2"]}]
+    }
+  ],
+  "cloud_config": {
+    "disk_types": [{"name": "large", "disk_size": 10240}, {"name": "small", "disk_size": 5120}],
+    "vm_types": [{"name": "medium", "cpu": 4}]
+  }
+}`
@@ -1,12 +1,13 @@
 package sessions
 
 import (
+	"context"
 	"time"
 )
 
 //go:generate counterfeiter . opsmanClient
 type opsmanClient interface {
-	Delete(endpoint string, timeout time.Duration) error
+	Delete(ctx context.Context, endpoint string, timeout time.Duration) error
 }
 
 func NewSessionManager(client opsmanClient) SessionManager {
@@ -21,8 +22,8 @@ type SessionManager struct {
 
 }
 
-func (manager SessionManager) ClearAll() error {
+func (manager SessionManager) ClearAll(ctx context.Context) error {
 
-	return manager.opsmanClient.Delete("/api/v0/sessions", 5*time.Minute)
+	return manager.opsmanClient.Delete(ctx, "/api/v0/sessions", 5*time.Minute)
 
 }
\ No newline at end of file
@@ -1,6 +1,7 @@
 package sessions_test
 
 import (
+	"context"
 	"github.com/pivotal-cloudops/omen/internal/sessions"
 
 	. "github.com/onsi/ginkgo"
@@ -18,10 +19,10 @@ var _ = Describe("Sessions", func() {
 			fakeClient.DeleteReturns(nil)
 
 			manager := sessions.NewSessionManager(fakeClient)
-			Expect(manager.ClearAll()).To(Succeed())
+			Expect(manager.ClearAll(context.Background())).To(Succeed())
 
 			Expect(fakeClient.DeleteCallCount()).To(Equal(1))
-			req, _ := fakeClient.DeleteArgsForCall(0)
+			_, req, _ := fakeClient.DeleteArgsForCall(0)
 			Expect(req).To(ContainSubstring("/api/v0/sessions"))
 		})
 
@@ -30,7 +31,7 @@ var _ = Describe("Sessions", func() {
 			fakeClient.DeleteReturns(errors.New("Something is wrong"))
 
 			manager := sessions.NewSessionManager(fakeClient)
-			Expect(manager.ClearAll()).ToNot(Succeed())
+			Expect(manager.ClearAll(context.Background())).ToNot(Succeed())
 		})
 
 	})
@@ -2,16 +2,18 @@
 package sessionsfakes
 
 import (
+	"context"
 	"sync"
 	"time"
 )
 
 type FakeOpsmanClient struct {
-	DeleteStub        func(endpoint string, timeout time.Duration) error
+	DeleteStub        func(context.Context, string, time.Duration) error
 	deleteMutex       sync.RWMutex
 	deleteArgsForCall []struct {
-		endpoint string
-		timeout  time.Duration
+		arg1 context.Context
+		arg2 string
+		arg3 time.Duration
 	}
 	deleteReturns struct {
 		result1 error
@@ -23,22 +25,24 @@ type FakeOpsmanClient struct {
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *FakeOpsmanClient) Delete(endpoint string, timeout time.Duration) error {
+func (fake *FakeOpsmanClient) Delete(arg1 context.Context, arg2 string, arg3 time.Duration) error {
 	fake.deleteMutex.Lock()
 	ret, specificReturn := fake.deleteReturnsOnCall[len(fake.deleteArgsForCall)]
 	fake.deleteArgsForCall = append(fake.deleteArgsForCall, struct {
-		endpoint string
-		timeout  time.Duration
-	}{endpoint, timeout})
-	fake.recordInvocation("Delete", []interface{}{endpoint, timeout})
+		arg1 context.Context
+		arg2 string
+		arg3 time.Duration
+	}{arg1, arg2, arg3})
+	fake.recordInvocation("Delete", []interface{}{arg1, arg2, arg3})
 	fake.deleteMutex.Unlock()
 	if fake.DeleteStub != nil {
-		return fake.DeleteStub(endpoint, timeout)
+		return fake.DeleteStub(arg1, arg2, arg3)
 	}
 	if specificReturn {
 		return ret.result1
 	}
-	return fake.deleteReturns.result1
+	fakeReturns := fake.deleteReturns
+	return fakeReturns.result1
 }
 
 func (fake *FakeOpsmanClient) DeleteCallCount() int {
@@ -47,13 +51,22 @@ func (fake *FakeOpsmanClient) DeleteCallCount() int {
 	return len(fake.deleteArgsForCall)
 }
 
-func (fake *FakeOpsmanClient) DeleteArgsForCall(i int) (string, time.Duration) {
+func (fake *FakeOpsmanClient) DeleteCalls(stub func(context.Context, string, time.Duration) error) {
+	fake.deleteMutex.Lock()
+	defer fake.deleteMutex.Unlock()
+	fake.DeleteStub = stub
+}
+
+func (fake *FakeOpsmanClient) DeleteArgsForCall(i int) (context.Context, string, time.Duration) {
 	fake.deleteMutex.RLock()
 	defer fake.deleteMutex.RUnlock()
-	return fake.deleteArgsForCall[i].endpoint, fake.deleteArgsForCall[i].timeout
+	argsForCall := fake.deleteArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
 }
 
 func (fake *FakeOpsmanClient) DeleteReturns(result1 error) {
+	fake.deleteMutex.Lock()
+	defer fake.deleteMutex.Unlock()
 	fake.DeleteStub = nil
 	fake.deleteReturns = struct {
 		result1 error
@@ -61,6 +74,8 @@ func (fake *FakeOpsmanClient) DeleteReturns(result1 error) {
 }
 
 func (fake *FakeOpsmanClient) DeleteReturnsOnCall(i int, result1 error) {
+	fake.deleteMutex.Lock()
+	defer fake.deleteMutex.Unlock()
 	fake.DeleteStub = nil
 	if fake.deleteReturnsOnCall == nil {
 		fake.deleteReturnsOnCall = make(map[int]struct {
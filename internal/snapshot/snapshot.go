@@ -0,0 +1,143 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pivotal-cloudops/omen/internal/manifest"
+	"github.com/pivotal-cloudops/omen/internal/redact"
+	"github.com/pivotal-cloudops/omen/internal/tile"
+)
+
+const FormatVersion = 1
+
+type TileVersion struct {
+	Type    string `json:"type"`
+	GUID    string `json:"guid"`
+	Version string `json:"version"`
+}
+
+type Metadata struct {
+	Foundation string        `json:"foundation"`
+	TakenAt    time.Time     `json:"taken_at"`
+	Redacted   bool          `json:"redacted"`
+	Tiles      []TileVersion `json:"tiles"`
+}
+
+// Snapshot is the file format of omen manifests --out: the deployed manifests
+// and cloud config of a foundation, plus where and when they were taken.
+type Snapshot struct {
+	Version  int      `json:"version"`
+	Metadata Metadata `json:"metadata"`
+	manifest.Manifests
+}
+
+func New(foundation string, takenAt time.Time, tiles tile.Tiles, manifests manifest.Manifests) Snapshot {
+	versions := make([]TileVersion, 0, len(tiles.Data))
+	for _, t := range tiles.Data {
+		versions = append(versions, TileVersion{Type: t.Type, GUID: t.GUID, Version: t.ProductVersion})
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].Type < versions[j].Type
+	})
+
+	return Snapshot{
+		Version: FormatVersion,
+		Metadata: Metadata{
+			Foundation: foundation,
+			TakenAt:    takenAt.UTC(),
+			Tiles:      versions,
+		},
+		Manifests: manifests,
+	}
+}
+
+func Read(path string) (Snapshot, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	var s Snapshot
+	err = json.Unmarshal(b, &s)
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("%s is not a valid snapshot: %s", path, err)
+	}
+
+	if s.Version != FormatVersion {
+		return Snapshot{}, fmt.Errorf("%s is not a valid snapshot: unsupported version %d, expected %d", path, s.Version, FormatVersion)
+	}
+
+	return s, nil
+}
+
+// Write saves the snapshot with its secrets masked unless showSecrets is set.
+// Redaction hashes are only comparable within a run, so snapshots compared
+// later can't keep them.
+func (s Snapshot) Write(path string, showSecrets bool) error {
+	var doc interface{} = s
+	if !showSecrets {
+		s.Metadata.Redacted = true
+
+		var err error
+		doc, err = redact.Mask(s)
+		if err != nil {
+			return err
+		}
+	}
+
+	b, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, b, 0600)
+}
+
+func (s Snapshot) Describe() string {
+	return fmt.Sprintf("%s at %s", s.Metadata.Foundation, s.Metadata.TakenAt.Format(time.RFC3339))
+}
+
+// TileChanges lists the tiles whose version differs between the snapshots, or
+// that only one of them has.
+func TileChanges(a Snapshot, b Snapshot) string {
+	before := map[string]string{}
+	for _, t := range a.Metadata.Tiles {
+		before[t.Type] = t.Version
+	}
+	after := map[string]string{}
+	for _, t := range b.Metadata.Tiles {
+		after[t.Type] = t.Version
+	}
+
+	var types []string
+	for t := range before {
+		types = append(types, t)
+	}
+	for t := range after {
+		if _, ok := before[t]; !ok {
+			types = append(types, t)
+		}
+	}
+	sort.Strings(types)
+
+	var lines []string
+	for _, t := range types {
+		oldVersion, inA := before[t]
+		newVersion, inB := after[t]
+		switch {
+		case !inA:
+			lines = append(lines, fmt.Sprintf("%s: added (%s)\n", t, newVersion))
+		case !inB:
+			lines = append(lines, fmt.Sprintf("%s: removed (%s)\n", t, oldVersion))
+		case oldVersion != newVersion:
+			lines = append(lines, fmt.Sprintf("%s: %s => %s\n", t, oldVersion, newVersion))
+		}
+	}
+
+	return strings.Join(lines, "")
+}
@@ -0,0 +1,99 @@
+package snapshot_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pivotal-cloudops/omen/internal/manifest"
+	"github.com/pivotal-cloudops/omen/internal/snapshot"
+	"github.com/pivotal-cloudops/omen/internal/tile"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Snapshot", func() {
+	var (
+		dir       string
+		takenAt   time.Time
+		tiles     tile.Tiles
+		manifests manifest.Manifests
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "snapshot")
+		Expect(err).NotTo(HaveOccurred())
+
+		takenAt = time.Date(2018, 6, 1, 12, 0, 0, 0, time.UTC)
+		tiles = tile.Tiles{Data: []*tile.Tile{
+			{Type: "p-redis", GUID: "p-redis-5678", ProductVersion: "1.13.0"},
+			{Type: "cf", GUID: "cf-1234", ProductVersion: "2.1.0"},
+		}}
+		manifests = manifest.Manifests{
+			Data:        []manifest.Manifest{{Name: "cf-1234", Update: map[string]interface{}{"password": "hunter2"}}},
+			CloudConfig: map[string]interface{}{"azs": []interface{}{"z1"}},
+		}
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("records the foundation, time and tile versions", func() {
+		s := snapshot.New("https://opsman.example.com", takenAt, tiles, manifests)
+
+		Expect(s.Version).To(Equal(snapshot.FormatVersion))
+		Expect(s.Metadata.Foundation).To(Equal("https://opsman.example.com"))
+		Expect(s.Metadata.TakenAt).To(Equal(takenAt))
+		Expect(s.Metadata.Tiles).To(Equal([]snapshot.TileVersion{
+			{Type: "cf", GUID: "cf-1234", Version: "2.1.0"},
+			{Type: "p-redis", GUID: "p-redis-5678", Version: "1.13.0"},
+		}))
+		Expect(s.Describe()).To(Equal("https://opsman.example.com at 2018-06-01T12:00:00Z"))
+	})
+
+	It("writes a redacted snapshot that can be read back", func() {
+		path := filepath.Join(dir, "snapshot.json")
+		Expect(snapshot.New("https://opsman.example.com", takenAt, tiles, manifests).Write(path, false)).To(Succeed())
+
+		s, err := snapshot.Read(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s.Metadata.Redacted).To(BeTrue())
+		Expect(s.Metadata.Foundation).To(Equal("https://opsman.example.com"))
+		Expect(s.Data[0].Name).To(Equal("cf-1234"))
+		Expect(s.Data[0].Update).To(Equal(map[string]interface{}{"password": "<redacted>"}))
+		Expect(s.CloudConfig).To(Equal(map[string]interface{}{"azs": []interface{}{"z1"}}))
+	})
+
+	It("keeps secrets when asked to", func() {
+		path := filepath.Join(dir, "snapshot.json")
+		Expect(snapshot.New("https://opsman.example.com", takenAt, tiles, manifests).Write(path, true)).To(Succeed())
+
+		s, err := snapshot.Read(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s.Metadata.Redacted).To(BeFalse())
+		Expect(s.Data[0].Update).To(Equal(map[string]interface{}{"password": "hunter2"}))
+	})
+
+	It("refuses files that are not snapshots", func() {
+		path := filepath.Join(dir, "manifests.json")
+		Expect(ioutil.WriteFile(path, []byte(`{"manifests": []}`), 0644)).To(Succeed())
+
+		_, err := snapshot.Read(path)
+		Expect(err).To(MatchError(ContainSubstring("is not a valid snapshot: unsupported version 0")))
+	})
+
+	It("lists tile version changes", func() {
+		a := snapshot.New("a", takenAt, tiles, manifests)
+		b := snapshot.New("b", takenAt, tile.Tiles{Data: []*tile.Tile{
+			{Type: "cf", GUID: "cf-1234", ProductVersion: "2.2.0"},
+			{Type: "p-mysql", GUID: "p-mysql-9", ProductVersion: "2.3.0"},
+		}}, manifests)
+
+		Expect(snapshot.TileChanges(a, b)).To(Equal("cf: 2.1.0 => 2.2.0\np-mysql: added (2.3.0)\np-redis: removed (1.13.0)\n"))
+		Expect(snapshot.TileChanges(a, a)).To(BeEmpty())
+	})
+})
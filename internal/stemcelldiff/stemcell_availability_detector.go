@@ -1,12 +1,12 @@
 package stemcelldiff
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
-	"net/http"
 	"reflect"
 	"strings"
+	"time"
 )
 
 type stemcellProduct struct {
@@ -72,9 +72,9 @@ func (o *availableStemcells) register(stemcellVersion string, stemcellOS string,
 	})
 }
 
-//go:generate counterfeiter . httpClient
-type httpClient interface {
-	Do(*http.Request) (*http.Response, error)
+//go:generate counterfeiter . opsmanClient
+type opsmanClient interface {
+	Get(ctx context.Context, endpoint string, timeout time.Duration) ([]byte, error)
 }
 
 //go:generate counterfeiter . reporter
@@ -83,24 +83,24 @@ type reporter interface {
 }
 
 type StemcellUpdateDetector struct {
-	Client   httpClient
+	Client   opsmanClient
 	Reporter reporter
 }
 
-func NewStemcellUpdateDetector(client httpClient, r reporter) StemcellUpdateDetector {
+func NewStemcellUpdateDetector(client opsmanClient, r reporter) StemcellUpdateDetector {
 	return StemcellUpdateDetector{Client: client, Reporter: r}
 }
 
-func (s *StemcellUpdateDetector) DetectMissingStemcells() error {
+func (s *StemcellUpdateDetector) DetectMissingStemcells(ctx context.Context) error {
 
-	updates, err := s.getStemcellUpdates()
+	updates, err := s.getStemcellUpdates(ctx)
 	if err != nil {
 		return err
 	}
 
 	var assignments omStemcellAssignments
 	if len(updates.StemcellUpdates) > 0 {
-		assignments, err = s.getStemcellAssignments()
+		assignments, err = s.getStemcellAssignments(ctx)
 		if err != nil {
 			return err
 		}
@@ -145,9 +145,9 @@ func products(updateEntry omStemcellUpdateEntry, assignments omStemcellAssignmen
 	return unupdatedProducts
 }
 
-func (s *StemcellUpdateDetector) getStemcellUpdates() (omStemcellUpdates, error) {
+func (s *StemcellUpdateDetector) getStemcellUpdates(ctx context.Context) (omStemcellUpdates, error) {
 	availableStemcellsPath := "/api/v0/pivotal_network/stemcell_updates"
-	availableStemcells, err := s.getContentForOmPath(availableStemcellsPath)
+	availableStemcells, err := s.getContentForOmPath(ctx, availableStemcellsPath)
 	if err != nil {
 		return omStemcellUpdates{}, err
 	}
@@ -161,9 +161,9 @@ func (s *StemcellUpdateDetector) getStemcellUpdates() (omStemcellUpdates, error)
 	return latestStemcells, nil
 }
 
-func (s *StemcellUpdateDetector) getStemcellAssignments() (omStemcellAssignments, error) {
+func (s *StemcellUpdateDetector) getStemcellAssignments(ctx context.Context) (omStemcellAssignments, error) {
 	availableStemcellsPath := "/api/v0/stemcell_assignments"
-	availableStemcells, err := s.getContentForOmPath(availableStemcellsPath)
+	availableStemcells, err := s.getContentForOmPath(ctx, availableStemcellsPath)
 	if err != nil {
 		return omStemcellAssignments{}, err
 	}
@@ -177,26 +177,8 @@ func (s *StemcellUpdateDetector) getStemcellAssignments() (omStemcellAssignments
 
 }
 
-func (s *StemcellUpdateDetector) getContentForOmPath(path string) ([]byte, error) {
-	req, err := http.NewRequest("GET", path, nil)
-
-	if err != nil {
-		return nil, err
-	}
-
-	response, err := s.Client.Do(req)
-
-	if err != nil {
-		return nil, err
-	}
-
-	reply, err := ioutil.ReadAll(response.Body)
-
-	if err != nil {
-		return nil, err
-	}
-
-	return reply, nil
+func (s *StemcellUpdateDetector) getContentForOmPath(ctx context.Context, path string) ([]byte, error) {
+	return s.Client.Get(ctx, path, 10*time.Minute)
 }
 
 func (s *omStemcellAssignments) findStemcellOS(productId string) string {
@@ -1,9 +1,9 @@
 package stemcelldiff_test
 
 import (
-	"io/ioutil"
-	"net/http"
+	"context"
 	"strings"
+	"time"
 
 	"fmt"
 	. "github.com/Benjamintf1/Expanded-Unmarshalled-Matchers"
@@ -105,22 +105,19 @@ const (
 var _ = Describe("StemcellAvailabilityDetector", func() {
 
 	table.DescribeTable("Stemcell reporting", func(stemcells, assignments, report string) {
-		client := stemcelldifffakes.FakeHttpClient{}
+		client := stemcelldifffakes.FakeOpsmanClient{}
 		rep := stemcelldifffakes.FakeReporter{}
-		client.DoStub = func(request *http.Request) (*http.Response, error) {
-			var response *http.Response
-			if strings.HasSuffix(request.URL.Path, "/stemcell_updates") {
-				response = &http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader(stemcells))}
-			} else if strings.HasSuffix(request.URL.Path, "/stemcell_assignments") {
-				response = &http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader(assignments))}
-			} else {
-				return nil, fmt.Errorf("unexpected URL %#v", request.URL)
+		client.GetStub = func(_ context.Context, endpoint string, _ time.Duration) ([]byte, error) {
+			if strings.HasSuffix(endpoint, "/stemcell_updates") {
+				return []byte(stemcells), nil
+			} else if strings.HasSuffix(endpoint, "/stemcell_assignments") {
+				return []byte(assignments), nil
 			}
-			return response, nil
+			return nil, fmt.Errorf("unexpected endpoint %s", endpoint)
 		}
 
 		detector := stemcelldiff.NewStemcellUpdateDetector(&client, &rep)
-		err := detector.DetectMissingStemcells()
+		err := detector.DetectMissingStemcells(context.Background())
 		Expect(err).NotTo(HaveOccurred())
 		output := rep.PrintReportArgsForCall(0)
 		Expect(output).To(MatchUnorderedJSON(report))
@@ -1,6 +1,9 @@
 package tile
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+)
 
 type Lister struct {
 	loader tilesLoader
@@ -11,7 +14,7 @@ const reportHeader = "Name\tGUID\tVersion\n----\t----\t-------\n"
 
 //go:generate counterfeiter . tilesLoader
 type tilesLoader interface {
-	LoadDeployed(bool) (Tiles, error)
+	LoadDeployed(context.Context, bool) (Tiles, error)
 }
 
 //go:generate counterfeiter . tableReporter
@@ -24,8 +27,8 @@ func NewTileLister(tl tilesLoader, ui tableReporter) Lister {
 	return Lister{loader: tl, ui: ui}
 }
 
-func (l Lister) Execute() error {
-	tiles, err := l.loader.LoadDeployed(false)
+func (l Lister) Execute(ctx context.Context) error {
+	tiles, err := l.loader.LoadDeployed(ctx, false)
 	if err != nil {
 		return err
 	}
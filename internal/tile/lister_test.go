@@ -1,6 +1,7 @@
 package tile_test
 
 import (
+	"context"
 	"errors"
 
 	. "github.com/onsi/ginkgo"
@@ -35,7 +36,7 @@ var _ = Describe("Lister", func() {
 	})
 
 	It("prints an empty report", func() {
-		err := subject.Execute()
+		err := subject.Execute(context.Background())
 		Expect(err).NotTo(HaveOccurred())
 
 		Expect(reporter.WriteCallCount()).To(Equal(1))
@@ -50,7 +51,7 @@ var _ = Describe("Lister", func() {
 		}
 		loader.LoadDeployedReturns(tiles, nil)
 
-		err := subject.Execute()
+		err := subject.Execute(context.Background())
 		Expect(err).NotTo(HaveOccurred())
 
 		Expect(reporter.WriteCallCount()).To(Equal(2))
@@ -73,14 +74,14 @@ var _ = Describe("Lister", func() {
 			return nil
 		}
 
-	    err := subject.Execute()
+	    err := subject.Execute(context.Background())
 	    Expect(err).NotTo(HaveOccurred())
 	    Expect(reporter.FlushCallCount()).To(Equal(1))
 	})
 
 	It("surfaces the underlying errors", func() {
 		loader.LoadDeployedReturns(tile.Tiles{}, errors.New("boom"))
-		err := subject.Execute()
+		err := subject.Execute(context.Background())
 
 		Expect(err).To(MatchError(errors.New("boom")))
 	})
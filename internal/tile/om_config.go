@@ -0,0 +1,251 @@
+package tile
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// OMConfig is a product's configuration in the format read by om configure-product.
+type OMConfig struct {
+	ProductName       string                 `yaml:"product-name"`
+	ProductProperties map[string]interface{} `yaml:"product-properties,omitempty"`
+	NetworkProperties map[string]interface{} `yaml:"network-properties,omitempty"`
+	ResourceConfig    map[string]interface{} `yaml:"resource-config,omitempty"`
+	ErrandConfig      map[string]interface{} `yaml:"errand-config,omitempty"`
+}
+
+// OMConfig converts the tile into om configure-product configuration. Read-only
+// and non-configurable fields are dropped. Credentials are dropped too, unless
+// placeholders is set, in which case they are templated as ((vars)).
+func (t Tile) OMConfig(placeholders bool) OMConfig {
+	return OMConfig{
+		ProductName:       t.Type,
+		ProductProperties: productProperties(t.Properties, placeholders),
+		NetworkProperties: networkProperties(t.Networks),
+		ResourceConfig:    resourceConfig(t.Resources),
+		ErrandConfig:      errandConfig(t.Errands),
+	}
+}
+
+// WriteOMConfig writes one <slug>.yml om configure-product file per tile.
+func (t Tiles) WriteOMConfig(path string, placeholders bool) error {
+	err := os.MkdirAll(path, 0755)
+	if err != nil {
+		return err
+	}
+
+	for _, t := range t.Data {
+		b, err := yaml.Marshal(t.OMConfig(placeholders))
+		if err != nil {
+			return err
+		}
+
+		err = ioutil.WriteFile(filepath.Join(path, t.Type+".yml"), b, 0644)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func productProperties(properties map[string]interface{}, placeholders bool) map[string]interface{} {
+	props, _ := properties["properties"].(map[string]interface{})
+
+	result := map[string]interface{}{}
+	for name, p := range props {
+		property, ok := p.(map[string]interface{})
+		if !ok || property["configurable"] != true {
+			continue
+		}
+
+		value, ok := propertyValue(name, property, placeholders)
+		if !ok {
+			continue
+		}
+		result[name] = map[string]interface{}{"value": value}
+	}
+
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
+func propertyValue(name string, property map[string]interface{}, placeholders bool) (interface{}, bool) {
+	if property["credential"] == true {
+		if !placeholders {
+			return nil, false
+		}
+		return placeholder(varName(name), property["type"]), true
+	}
+
+	if property["type"] == "collection" {
+		return CollectionValue(name, property["value"], placeholders), true
+	}
+
+	value := property["value"]
+	return value, value != nil
+}
+
+// CollectionValue converts the items of a collection property from the staged
+// product properties into the plain values accepted by Ops Manager and om.
+// Credential fields are left out, or templated when placeholders is set.
+func CollectionValue(name string, value interface{}, placeholders bool) []interface{} {
+	items, _ := value.([]interface{})
+
+	result := make([]interface{}, 0, len(items))
+	for i, item := range items {
+		fields, _ := item.(map[string]interface{})
+
+		entry := map[string]interface{}{}
+		for fieldName, f := range fields {
+			field, ok := f.(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			if field["credential"] == true {
+				if placeholders {
+					entry[fieldName] = placeholder(fmt.Sprintf("%s_%d_%s", varName(name), i, fieldName), field["type"])
+				}
+				continue
+			}
+
+			if field["value"] != nil {
+				entry[fieldName] = field["value"]
+			}
+		}
+		result = append(result, entry)
+	}
+	return result
+}
+
+func placeholder(name string, propertyType interface{}) interface{} {
+	switch propertyType {
+	case "secret":
+		return map[string]interface{}{"secret": "((" + name + "))"}
+	case "simple_credentials":
+		return map[string]interface{}{
+			"identity": "((" + name + "_identity))",
+			"password": "((" + name + "_password))",
+		}
+	case "rsa_cert_credentials":
+		return map[string]interface{}{
+			"cert_pem":        "((" + name + "_cert_pem))",
+			"private_key_pem": "((" + name + "_private_key_pem))",
+		}
+	case "rsa_pkey_credentials":
+		return map[string]interface{}{"private_key_pem": "((" + name + "_private_key_pem))"}
+	case "salted_credentials":
+		return map[string]interface{}{
+			"identity": "((" + name + "_identity))",
+			"password": "((" + name + "_password))",
+			"salt":     "((" + name + "_salt))",
+		}
+	}
+	return "((" + name + "))"
+}
+
+func varName(property string) string {
+	return strings.Replace(strings.TrimLeft(property, "."), ".", "_", -1)
+}
+
+func networkProperties(networks map[string]interface{}) map[string]interface{} {
+	n, _ := networks["networks_and_azs"].(map[string]interface{})
+	if len(n) == 0 {
+		return nil
+	}
+	return n
+}
+
+func resourceConfig(resources map[string]interface{}) map[string]interface{} {
+	jobs, _ := resources["resources"].([]interface{})
+
+	result := map[string]interface{}{}
+	for _, j := range jobs {
+		job, ok := j.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		identifier, ok := job["identifier"].(string)
+		if !ok {
+			continue
+		}
+
+		result[identifier] = JobResourceConfig(job)
+	}
+
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
+// JobResourceConfig converts a job from the staged product resources into the
+// resource config accepted by Ops Manager and om.
+func JobResourceConfig(job map[string]interface{}) map[string]interface{} {
+	config := map[string]interface{}{
+		"instances":     automatic(job["instances"]),
+		"instance_type": map[string]interface{}{"id": automatic(job["instance_type_id"])},
+	}
+	if size, ok := job["persistent_disk_mb"]; ok {
+		config["persistent_disk"] = map[string]interface{}{"size_mb": diskSize(size)}
+	}
+	return config
+}
+
+// diskSize formats a disk size the way om expects it, as a string of whole
+// megabytes rather than in the exponent notation large floats print with.
+func diskSize(size interface{}) string {
+	switch s := automatic(size).(type) {
+	case float64:
+		return strconv.FormatFloat(s, 'f', -1, 64)
+	case string:
+		return s
+	default:
+		return fmt.Sprint(s)
+	}
+}
+
+func automatic(v interface{}) interface{} {
+	if v == nil || v == "" {
+		return "automatic"
+	}
+	return v
+}
+
+func errandConfig(errands map[string]interface{}) map[string]interface{} {
+	list, _ := errands["errands"].([]interface{})
+
+	result := map[string]interface{}{}
+	for _, e := range list {
+		errand, ok := e.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, ok := errand["name"].(string)
+		if !ok {
+			continue
+		}
+
+		config := map[string]interface{}{}
+		if v, ok := errand["post_deploy"]; ok && v != nil {
+			config["post-deploy-state"] = v
+		}
+		if v, ok := errand["pre_delete"]; ok && v != nil {
+			config["pre-delete-state"] = v
+		}
+		result[name] = config
+	}
+
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
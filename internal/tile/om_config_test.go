@@ -0,0 +1,165 @@
+package tile_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pivotal-cloudops/omen/internal/tile"
+)
+
+const stagedTile = `{
+  "guid": "cf-1234",
+  "type": "cf",
+  "properties": {
+    "properties": {
+      ".properties.system_domain": {"type": "wildcard_domain", "configurable": true, "credential": false, "value": "sys.example.com"},
+      ".properties.optional_thing": {"type": "string", "configurable": true, "credential": false, "value": null},
+      ".cloud_controller.encrypt_key": {"type": "secret", "configurable": true, "credential": true, "value": {"secret": "***"}},
+      ".properties.uaa_admin": {"type": "simple_credentials", "configurable": true, "credential": true, "value": {"identity": "admin", "password": "***"}},
+      ".properties.generated_guid": {"type": "uuid", "configurable": false, "credential": false, "value": "abc"},
+      ".properties.networking_poe_ssl_certs": {"type": "collection", "configurable": true, "credential": false, "value": [
+        {
+          "name": {"type": "string", "configurable": true, "credential": false, "value": "default"},
+          "certificate": {"type": "rsa_cert_credentials", "configurable": true, "credential": true, "value": {"cert_pem": "***"}}
+        }
+      ]}
+    }
+  },
+  "networks_and_azs": {
+    "networks_and_azs": {
+      "network": {"name": "ert-network"},
+      "singleton_availability_zone": {"name": "us-central1-a"}
+    }
+  },
+  "resources": {
+    "resources": [
+      {"identifier": "router", "instances": 3, "instance_type_id": "large", "instance_type_best_fit": "micro"},
+      {"identifier": "mysql", "instances": "", "instance_type_id": "", "persistent_disk_mb": 10240, "persistent_disk_best_fit": 1024}
+    ]
+  },
+  "errands": {
+    "errands": [
+      {"name": "smoke-tests", "post_deploy": true},
+      {"name": "delete-all-apps", "pre_delete": "when-changed"}
+    ]
+  }
+}`
+
+var _ = Describe("OMConfig", func() {
+	var t tile.Tile
+
+	BeforeEach(func() {
+		t = tile.Tile{}
+		err := json.Unmarshal([]byte(stagedTile), &t)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("converts the staged tile into om configure-product configuration", func() {
+		c := t.OMConfig(false)
+
+		Expect(c.ProductName).To(Equal("cf"))
+		Expect(c.ProductProperties).To(Equal(map[string]interface{}{
+			".properties.system_domain": map[string]interface{}{"value": "sys.example.com"},
+			".properties.networking_poe_ssl_certs": map[string]interface{}{"value": []interface{}{
+				map[string]interface{}{"name": "default"},
+			}},
+		}))
+		Expect(c.NetworkProperties).To(Equal(map[string]interface{}{
+			"network":                     map[string]interface{}{"name": "ert-network"},
+			"singleton_availability_zone": map[string]interface{}{"name": "us-central1-a"},
+		}))
+		Expect(c.ResourceConfig).To(Equal(map[string]interface{}{
+			"router": map[string]interface{}{
+				"instances":     float64(3),
+				"instance_type": map[string]interface{}{"id": "large"},
+			},
+			"mysql": map[string]interface{}{
+				"instances":       "automatic",
+				"instance_type":   map[string]interface{}{"id": "automatic"},
+				"persistent_disk": map[string]interface{}{"size_mb": "10240"},
+			},
+		}))
+		Expect(c.ErrandConfig).To(Equal(map[string]interface{}{
+			"smoke-tests":     map[string]interface{}{"post-deploy-state": true},
+			"delete-all-apps": map[string]interface{}{"pre-delete-state": "when-changed"},
+		}))
+	})
+
+	It("keeps large disk sizes in whole megabytes", func() {
+		config := tile.JobResourceConfig(map[string]interface{}{"instances": float64(1), "instance_type_id": "xlarge", "persistent_disk_mb": float64(1048576)})
+
+		Expect(config["persistent_disk"]).To(Equal(map[string]interface{}{"size_mb": "1048576"}))
+	})
+
+	It("templates credentials as placeholders when asked for", func() {
+		c := t.OMConfig(true)
+
+		Expect(c.ProductProperties).To(HaveKeyWithValue(".cloud_controller.encrypt_key", map[string]interface{}{
+			"value": map[string]interface{}{"secret": "((cloud_controller_encrypt_key))"},
+		}))
+		Expect(c.ProductProperties).To(HaveKeyWithValue(".properties.uaa_admin", map[string]interface{}{
+			"value": map[string]interface{}{
+				"identity": "((properties_uaa_admin_identity))",
+				"password": "((properties_uaa_admin_password))",
+			},
+		}))
+		Expect(c.ProductProperties).To(HaveKeyWithValue(".properties.networking_poe_ssl_certs", map[string]interface{}{
+			"value": []interface{}{
+				map[string]interface{}{
+					"name": "default",
+					"certificate": map[string]interface{}{
+						"cert_pem":        "((properties_networking_poe_ssl_certs_0_certificate_cert_pem))",
+						"private_key_pem": "((properties_networking_poe_ssl_certs_0_certificate_private_key_pem))",
+					},
+				},
+			},
+		}))
+		Expect(c.ProductProperties).NotTo(HaveKey(".properties.generated_guid"))
+	})
+
+	It("writes one YAML file per product", func() {
+		tmpdir, err := ioutil.TempDir("", "")
+		Expect(err).NotTo(HaveOccurred())
+		defer os.RemoveAll(tmpdir)
+
+		tiles := tile.Tiles{Data: []*tile.Tile{&t}}
+		err = tiles.WriteOMConfig(tmpdir, false)
+		Expect(err).NotTo(HaveOccurred())
+
+		b, err := ioutil.ReadFile(filepath.Join(tmpdir, "cf.yml"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(b)).To(Equal(`product-name: cf
+product-properties:
+  .properties.networking_poe_ssl_certs:
+    value:
+    - name: default
+  .properties.system_domain:
+    value: sys.example.com
+network-properties:
+  network:
+    name: ert-network
+  singleton_availability_zone:
+    name: us-central1-a
+resource-config:
+  mysql:
+    instance_type:
+      id: automatic
+    instances: automatic
+    persistent_disk:
+      size_mb: "10240"
+  router:
+    instance_type:
+      id: large
+    instances: 3
+errand-config:
+  delete-all-apps:
+    pre-delete-state: when-changed
+  smoke-tests:
+    post-deploy-state: true
+`))
+	})
+})
@@ -2,16 +2,18 @@
 package tilefakes
 
 import (
+	"context"
 	"sync"
 
 	"github.com/pivotal-cloudops/omen/internal/tile"
 )
 
 type FakeTilesLoader struct {
-	LoadDeployedStub        func(bool) (tile.Tiles, error)
+	LoadDeployedStub        func(context.Context, bool) (tile.Tiles, error)
 	loadDeployedMutex       sync.RWMutex
 	loadDeployedArgsForCall []struct {
-		arg1 bool
+		arg1 context.Context
+		arg2 bool
 	}
 	loadDeployedReturns struct {
 		result1 tile.Tiles
@@ -25,21 +27,23 @@ type FakeTilesLoader struct {
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *FakeTilesLoader) LoadDeployed(arg1 bool) (tile.Tiles, error) {
+func (fake *FakeTilesLoader) LoadDeployed(arg1 context.Context, arg2 bool) (tile.Tiles, error) {
 	fake.loadDeployedMutex.Lock()
 	ret, specificReturn := fake.loadDeployedReturnsOnCall[len(fake.loadDeployedArgsForCall)]
 	fake.loadDeployedArgsForCall = append(fake.loadDeployedArgsForCall, struct {
-		arg1 bool
-	}{arg1})
-	fake.recordInvocation("LoadDeployed", []interface{}{arg1})
+		arg1 context.Context
+		arg2 bool
+	}{arg1, arg2})
+	fake.recordInvocation("LoadDeployed", []interface{}{arg1, arg2})
 	fake.loadDeployedMutex.Unlock()
 	if fake.LoadDeployedStub != nil {
-		return fake.LoadDeployedStub(arg1)
+		return fake.LoadDeployedStub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	return fake.loadDeployedReturns.result1, fake.loadDeployedReturns.result2
+	fakeReturns := fake.loadDeployedReturns
+	return fakeReturns.result1, fakeReturns.result2
 }
 
 func (fake *FakeTilesLoader) LoadDeployedCallCount() int {
@@ -48,13 +52,22 @@ func (fake *FakeTilesLoader) LoadDeployedCallCount() int {
 	return len(fake.loadDeployedArgsForCall)
 }
 
-func (fake *FakeTilesLoader) LoadDeployedArgsForCall(i int) bool {
+func (fake *FakeTilesLoader) LoadDeployedCalls(stub func(context.Context, bool) (tile.Tiles, error)) {
+	fake.loadDeployedMutex.Lock()
+	defer fake.loadDeployedMutex.Unlock()
+	fake.LoadDeployedStub = stub
+}
+
+func (fake *FakeTilesLoader) LoadDeployedArgsForCall(i int) (context.Context, bool) {
 	fake.loadDeployedMutex.RLock()
 	defer fake.loadDeployedMutex.RUnlock()
-	return fake.loadDeployedArgsForCall[i].arg1
+	argsForCall := fake.loadDeployedArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
 }
 
 func (fake *FakeTilesLoader) LoadDeployedReturns(result1 tile.Tiles, result2 error) {
+	fake.loadDeployedMutex.Lock()
+	defer fake.loadDeployedMutex.Unlock()
 	fake.LoadDeployedStub = nil
 	fake.loadDeployedReturns = struct {
 		result1 tile.Tiles
@@ -63,6 +76,8 @@ func (fake *FakeTilesLoader) LoadDeployedReturns(result1 tile.Tiles, result2 err
 }
 
 func (fake *FakeTilesLoader) LoadDeployedReturnsOnCall(i int, result1 tile.Tiles, result2 error) {
+	fake.loadDeployedMutex.Lock()
+	defer fake.loadDeployedMutex.Unlock()
 	fake.LoadDeployedStub = nil
 	if fake.loadDeployedReturnsOnCall == nil {
 		fake.loadDeployedReturnsOnCall = make(map[int]struct {
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"sort"
 
 	"github.com/pkg/errors"
 )
@@ -29,6 +30,30 @@ type tileFinders struct {
 	other func(string) (Tile, error)
 }
 
+// ConfigDocument returns the configuration of the tiles as a list of products
+// named by slug, ready to be diffed.
+func (t Tiles) ConfigDocument() map[string]interface{} {
+	tiles := make([]*Tile, len(t.Data))
+	copy(tiles, t.Data)
+	sort.Slice(tiles, func(i, j int) bool {
+		return tiles[i].Type < tiles[j].Type
+	})
+
+	products := make([]interface{}, 0, len(tiles))
+	for _, t := range tiles {
+		products = append(products, map[string]interface{}{
+			"name":             t.Type,
+			"version":          t.ProductVersion,
+			"properties":       t.Properties,
+			"resources":        t.Resources,
+			"errands":          t.Errands,
+			"networks_and_azs": t.Networks,
+		})
+	}
+
+	return map[string]interface{}{"products": products}
+}
+
 func (t Tiles) FindBySlug(slug string) (Tile, error) {
 	for _, t := range t.Data {
 		if t.Type == slug {
@@ -69,6 +94,49 @@ func (t Tiles) Write(path string) error {
 	return nil
 }
 
+// Read loads the tiles written by Write, one per directory under path. Files
+// missing from a tile's directory leave that part of its configuration empty.
+func Read(path string) (Tiles, error) {
+	entries, err := ioutil.ReadDir(path)
+	if err != nil {
+		return Tiles{}, err
+	}
+
+	tiles := Tiles{}
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+
+		t := &Tile{Type: e.Name()}
+		dir := path + "/" + e.Name()
+		files := []struct {
+			filename string
+			member   *map[string]interface{}
+		}{
+			{"networks_and_azs.json", &t.Networks},
+			{"errands.json", &t.Errands},
+			{"properties.json", &t.Properties},
+			{"resources.json", &t.Resources},
+		}
+		for _, f := range files {
+			b, err := ioutil.ReadFile(dir + "/" + f.filename)
+			if os.IsNotExist(err) {
+				continue
+			}
+			if err != nil {
+				return Tiles{}, err
+			}
+			err = json.Unmarshal(b, f.member)
+			if err != nil {
+				return Tiles{}, errors.Wrap(err, dir+"/"+f.filename)
+			}
+		}
+		tiles.Data = append(tiles.Data, t)
+	}
+	return tiles, nil
+}
+
 func (t Tiles) FindBySlugsOrGUIDs(products []string) ([]*Tile, error) {
 	if len(products) == 0 {
 		return []*Tile{}, nil
@@ -1,9 +1,12 @@
 package tile
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"time"
+
+	"github.com/pivotal-cloudops/omen/internal/parallel"
 )
 
 type productStatus string
@@ -14,27 +17,28 @@ const (
 )
 
 type omClient interface {
-	Get(endpoint string, timeout time.Duration) ([]byte, error)
+	Get(ctx context.Context, endpoint string, timeout time.Duration) ([]byte, error)
 }
 
 type Loader struct {
-	client omClient
+	client      omClient
+	parallelism int
 }
 
-func NewTilesLoader(omClient omClient) Loader {
-	return Loader{client: omClient}
+func NewTilesLoader(omClient omClient, parallelism int) Loader {
+	return Loader{client: omClient, parallelism: parallelism}
 }
 
-func (l Loader) LoadStaged(fetchTileMetadata bool) (Tiles, error) {
-	return l.load(fetchTileMetadata, staged)
+func (l Loader) LoadStaged(ctx context.Context, fetchTileMetadata bool) (Tiles, error) {
+	return l.load(ctx, fetchTileMetadata, staged)
 }
 
-func (l Loader) LoadDeployed(fetchTileMetadata bool) (Tiles, error) {
-	return l.load(fetchTileMetadata, deployed)
+func (l Loader) LoadDeployed(ctx context.Context, fetchTileMetadata bool) (Tiles, error) {
+	return l.load(ctx, fetchTileMetadata, deployed)
 }
 
-func (l Loader) load(fetchTileMetadata bool, status productStatus) (Tiles, error) {
-	b, err := l.client.Get(fmt.Sprintf("/api/v0/%s/products", status), 10*time.Minute)
+func (l Loader) load(ctx context.Context, fetchTileMetadata bool, status productStatus) (Tiles, error) {
+	b, err := l.client.Get(ctx, fmt.Sprintf("/api/v0/%s/products", status), 10*time.Minute)
 
 	if err != nil {
 		return Tiles{}, err
@@ -47,38 +51,38 @@ func (l Loader) load(fetchTileMetadata bool, status productStatus) (Tiles, error
 	}
 
 	if fetchTileMetadata {
-		for _, d := range data {
-			err = l.loadTileMetadata(d, status)
-			if err != nil {
-				return Tiles{}, err
-			}
+		err = l.loadTilesMetadata(ctx, data, status)
+		if err != nil {
+			return Tiles{}, err
 		}
 	}
 
 	return Tiles{data}, nil
 }
 
-func (l Loader) loadTileMetadata(t *Tile, status productStatus) error {
-	urlsToPointer := []struct {
+// loadTilesMetadata fetches the networks, errands, resources and properties of
+// all tiles, up to the loader's parallelism at a time.
+func (l Loader) loadTilesMetadata(ctx context.Context, tiles []*Tile, status productStatus) error {
+	type request struct {
 		url     string
 		pointer *map[string]interface{}
-	}{
-		{"/api/v0/%s/products/%s/networks_and_azs", &t.Networks},
-		{"/api/v0/%s/products/%s/errands", &t.Errands},
-		{"/api/v0/%s/products/%s/resources", &t.Resources},
-		{"/api/v0/%s/products/%s/properties", &t.Properties},
 	}
 
-	for _, up := range urlsToPointer {
-		data, err := l.client.Get(fmt.Sprintf(up.url, status, t.GUID), 10*time.Minute)
-		if err != nil {
-			return err
-		}
-		err = json.Unmarshal(data, up.pointer)
+	var requests []request
+	for _, t := range tiles {
+		requests = append(requests,
+			request{fmt.Sprintf("/api/v0/%s/products/%s/networks_and_azs", status, t.GUID), &t.Networks},
+			request{fmt.Sprintf("/api/v0/%s/products/%s/errands", status, t.GUID), &t.Errands},
+			request{fmt.Sprintf("/api/v0/%s/products/%s/resources", status, t.GUID), &t.Resources},
+			request{fmt.Sprintf("/api/v0/%s/products/%s/properties", status, t.GUID), &t.Properties},
+		)
+	}
+
+	return parallel.Run(ctx, len(requests), l.parallelism, func(ctx context.Context, i int) error {
+		data, err := l.client.Get(ctx, requests[i].url, 10*time.Minute)
 		if err != nil {
 			return err
 		}
-	}
-
-	return nil
+		return json.Unmarshal(data, requests[i].pointer)
+	})
 }
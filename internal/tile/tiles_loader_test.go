@@ -1,6 +1,7 @@
 package tile_test
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -11,7 +12,9 @@ import (
 	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
 	"github.com/pivotal-cloudops/omen/internal/fakes"
+	"github.com/pivotal-cloudops/omen/internal/opsman"
 	"github.com/pivotal-cloudops/omen/internal/tile"
+	"github.com/pivotal-cloudops/omen/internal/userio"
 )
 
 var _ = Describe("Tiles Loader", func() {
@@ -27,8 +30,8 @@ var _ = Describe("Tiles Loader", func() {
 				}
 			},
 		}
-		loader := tile.NewTilesLoader(fakeOMClient)
-		tiles, err := loader.LoadDeployed(false)
+		loader := tile.NewTilesLoader(fakeOMClient, 4)
+		tiles, err := loader.LoadDeployed(context.Background(), false)
 		Expect(err).NotTo(HaveOccurred())
 		data := tiles.Data
 		Expect(data).To(HaveLen(1))
@@ -46,8 +49,8 @@ var _ = Describe("Tiles Loader", func() {
 					}
 				},
 			}
-			loader := tile.NewTilesLoader(fakeOMClient)
-			tiles, err := loader.LoadStaged(false)
+			loader := tile.NewTilesLoader(fakeOMClient, 4)
+			tiles, err := loader.LoadStaged(context.Background(), false)
 			Expect(err).NotTo(HaveOccurred())
 			data := tiles.Data
 			Expect(data).To(HaveLen(1))
@@ -86,7 +89,7 @@ var _ = Describe("Tiles Loader", func() {
 				},
 			}
 
-			loader := tile.NewTilesLoader(fakeOMClient)
+			loader := tile.NewTilesLoader(fakeOMClient, 4)
 
 			var (
 				tiles tile.Tiles
@@ -95,9 +98,9 @@ var _ = Describe("Tiles Loader", func() {
 
 			switch status {
 			case "staged":
-				tiles, err = loader.LoadStaged(true)
+				tiles, err = loader.LoadStaged(context.Background(), true)
 			case "deployed":
-				tiles, err = loader.LoadDeployed(true)
+				tiles, err = loader.LoadDeployed(context.Background(), true)
 			default:
 				err = errors.New("invalid product status")
 			}
@@ -126,8 +129,8 @@ var _ = Describe("Tiles Loader", func() {
 				return nil, errors.New("failed")
 			},
 		}
-		finder := tile.NewTilesLoader(fakeOMClient)
-		_, err := finder.LoadStaged(true)
+		finder := tile.NewTilesLoader(fakeOMClient, 4)
+		_, err := finder.LoadStaged(context.Background(), true)
 		Expect(err).To(HaveOccurred())
 	})
 
@@ -151,10 +154,29 @@ var _ = Describe("Tiles Loader", func() {
 			},
 		}
 
-		finder := tile.NewTilesLoader(fakeOMClient)
-		_, err := finder.LoadStaged(true)
+		finder := tile.NewTilesLoader(fakeOMClient, 4)
+		_, err := finder.LoadStaged(context.Background(), true)
 		Expect(err).To(HaveOccurred())
 		Expect(err.Error()).To(Equal("loading networks failed"))
 	})
 
+	It("keeps the exit code of a failed metadata request", func() {
+		fakeOMClient := fakes.FakeOMClient{
+			GetFunc: func(endpoint string) ([]byte, error) {
+				switch endpoint {
+				case "/api/v0/staged/products":
+					return ioutil.ReadFile("testdata/tiles.json")
+				case "/api/v0/staged/products/guid/resources":
+					return nil, &opsman.Error{Kind: opsman.NotFound, Method: "GET", Endpoint: endpoint, StatusCode: 404}
+				default:
+					return []byte(`{}`), nil
+				}
+			},
+		}
+
+		finder := tile.NewTilesLoader(fakeOMClient, 4)
+		_, err := finder.LoadStaged(context.Background(), true)
+		Expect(opsman.KindOf(err)).To(Equal(opsman.NotFound))
+		Expect(userio.ExitCode(err)).To(Equal(3))
+	})
 })
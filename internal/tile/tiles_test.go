@@ -52,6 +52,32 @@ var _ = Describe("Tiles", func() {
 		})
 	})
 
+	Describe("Read", func() {
+		It("reads back the Tiles written by #Write", func() {
+			var t tile.Tile
+			f, err := ioutil.ReadFile("testdata/expected_tile.json")
+			Expect(err).NotTo(HaveOccurred())
+			err = json.Unmarshal(f, &t)
+			Expect(err).NotTo(HaveOccurred())
+
+			tmpdir, err := ioutil.TempDir("", "")
+			Expect(err).NotTo(HaveOccurred())
+			defer os.RemoveAll(tmpdir)
+
+			err = tile.Tiles{Data: []*tile.Tile{&t}}.Write(tmpdir)
+			Expect(err).NotTo(HaveOccurred())
+
+			tiles, err := tile.Read(tmpdir)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tiles.Data).To(HaveLen(1))
+			Expect(tiles.Data[0].Type).To(Equal("cf"))
+			Expect(tiles.Data[0].Properties).To(Equal(t.Properties))
+			Expect(tiles.Data[0].Resources).To(Equal(t.Resources))
+			Expect(tiles.Data[0].Networks).To(Equal(t.Networks))
+			Expect(tiles.Data[0].Errands).To(Equal(t.Errands))
+		})
+	})
+
 	Describe("#FindBySlugsOrGUIDs", func() {
 		It("returns an empty slice and no error for empty input", func() {
 			t := tile.Tiles{}
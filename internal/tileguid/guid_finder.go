@@ -1,15 +1,17 @@
 package tileguid
 
 import (
+	"context"
+
 	"github.com/pivotal-cloudops/omen/internal/tile"
 )
 
 type tileLoader interface {
-	LoadDeployed(fetchTileMetadata bool) (tile.Tiles, error)
+	LoadDeployed(ctx context.Context, fetchTileMetadata bool) (tile.Tiles, error)
 }
 
-func FindGuid(tileLoader tileLoader, productSlug string) (string, error) {
-	tiles, err := tileLoader.LoadDeployed(false)
+func FindGuid(ctx context.Context, tileLoader tileLoader, productSlug string) (string, error) {
+	tiles, err := tileLoader.LoadDeployed(ctx, false)
 	if err != nil {
 		return "", err
 	}
@@ -1,6 +1,7 @@
 package tileguid_test
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	. "github.com/onsi/ginkgo"
@@ -14,7 +15,7 @@ import (
 var _ = Describe("Guid finder", func()	 {
 	Context("non-existing product", func() {
 		It("returns expected error", func() {
-			guid, err := tileguid.FindGuid(setupFakeTilesLoader(), "whatever")
+			guid, err := tileguid.FindGuid(context.Background(), setupFakeTilesLoader(), "whatever")
 
 			Expect(guid).To(BeEmpty())
 			Expect(err).To(MatchError("product whatever not found"))
@@ -23,7 +24,7 @@ var _ = Describe("Guid finder", func()	 {
 
 	Context("existing product", func() {
 		It("returns the guid of the product and no error", func() {
-			guid, err := tileguid.FindGuid(setupFakeTilesLoader(), "elastic-runtime")
+			guid, err := tileguid.FindGuid(context.Background(), setupFakeTilesLoader(), "elastic-runtime")
 			Expect(guid).To(Equal("cf-4f9edbd1992fd81250e5"))
 			Expect(err).To(Not(HaveOccurred()))
 		})
@@ -35,7 +36,7 @@ var _ = Describe("Guid finder", func()	 {
 			tilesLoader.DeployedResponseFunc = func(b bool) (tile.Tiles, error) {
 				return tile.Tiles{}, errors.New("Network error")
 			}
-			guid, err := tileguid.FindGuid(tilesLoader, "whatever")
+			guid, err := tileguid.FindGuid(context.Background(), tilesLoader, "whatever")
 			Expect(guid).To(BeEmpty())
 			Expect(err).To(MatchError("Network error"))
 		})
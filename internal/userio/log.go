@@ -0,0 +1,53 @@
+package userio
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+type LogLevel int
+
+const (
+	LevelError LogLevel = iota
+	LevelWarn
+	LevelInfo
+	LevelDebug
+)
+
+var logLevelNames = []string{"error", "warn", "info", "debug"}
+
+func ParseLogLevel(name string) (LogLevel, error) {
+	for i, n := range logLevelNames {
+		if strings.EqualFold(name, n) {
+			return LogLevel(i), nil
+		}
+	}
+	return LevelInfo, fmt.Errorf("invalid log level %q, expected one of %s", name, strings.Join(logLevelNames, ", "))
+}
+
+// Logger prints omen's own progress messages, leaving out those below its
+// level.
+type Logger struct {
+	Out   io.Writer
+	Level LogLevel
+}
+
+func (l Logger) Debugf(format string, args ...interface{}) {
+	l.logf(LevelDebug, format, args...)
+}
+
+func (l Logger) Infof(format string, args ...interface{}) {
+	l.logf(LevelInfo, format, args...)
+}
+
+func (l Logger) Warnf(format string, args ...interface{}) {
+	l.logf(LevelWarn, format, args...)
+}
+
+func (l Logger) logf(level LogLevel, format string, args ...interface{}) {
+	if level > l.Level {
+		return
+	}
+	fmt.Fprintf(l.Out, format+"\n", args...)
+}
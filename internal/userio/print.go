@@ -1,12 +1,26 @@
 package userio
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"strings"
+
+	"github.com/pkg/errors"
 )
 
-type ReportPrinter struct{}
+type ReportPrinter struct {
+	// JSON is set for commands printing a machine-readable result on stdout.
+	// Failures then print a failed result document there instead of the error,
+	// which goes to stderr.
+	JSON bool
+}
+
+type failedResult struct {
+	Status string `json:"status"`
+	Error  string `json:"error"`
+}
 
 func (rp ReportPrinter) PrintReport(report string) {
 	if strings.HasSuffix(report, "\n") {
@@ -16,9 +30,41 @@ func (rp ReportPrinter) PrintReport(report string) {
 	}
 }
 
+type exitCoder interface {
+	ExitCode() int
+}
+
+// Fail prints the error and exits, with the exit code the error asks for if
+// it has one.
 func (rp ReportPrinter) Fail(err error) {
-	if err != nil {
+	if err == nil {
+		return
+	}
+
+	if rp.JSON {
+		b, _ := json.Marshal(failedResult{Status: "failed", Error: err.Error()})
+		fmt.Println(string(b))
+		fmt.Fprintln(os.Stderr, err.Error())
+	} else {
 		fmt.Println(err.Error())
-		os.Exit(1)
 	}
+	os.Exit(ExitCode(err))
+}
+
+// ExitCode is the exit status for err: the one it asks for, the interrupt and
+// timeout statuses of Ops Manager requests when it comes from a cancelled
+// context, or 1.
+func ExitCode(err error) int {
+	cause := errors.Cause(err)
+	if e, ok := cause.(exitCoder); ok {
+		return e.ExitCode()
+	}
+
+	switch cause {
+	case context.Canceled:
+		return 130
+	case context.DeadlineExceeded:
+		return 7
+	}
+	return 1
 }
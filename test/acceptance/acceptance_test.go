@@ -1,6 +1,8 @@
 package acceptance
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"os/exec"
 
 	. "github.com/onsi/ginkgo"
@@ -39,4 +41,38 @@ var _ = Describe("omen acceptance", func() {
 			Eventually(func() string { return string(session.Out.Contents()) }, timeout).Should(ContainSubstring("Opsman client secret is required."))
 		})
 	})
+
+	Describe("exit codes", func() {
+		var server *httptest.Server
+
+		BeforeEach(func() {
+			server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusUnauthorized)
+				w.Write([]byte(`{"error": "unauthorized", "error_description": "Bad credentials"}`))
+			}))
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		run := func(args ...string) *gexec.Session {
+			args = append([]string{"-t=" + server.URL, "-u=user", "-p=wrong", "--skip-ssl-validation"}, args...)
+			session, err := gexec.Start(exec.Command(pathToOmenCLI, args...), GinkgoWriter, GinkgoWriter)
+			Expect(err).ToNot(HaveOccurred())
+			return session
+		}
+
+		It("should exit with 4 when tile-guid cannot log in", func() {
+			session := run("tile-guid", "cf")
+			Eventually(session, 10).Should(gexec.Exit(4))
+			Expect(string(session.Out.Contents())).To(ContainSubstring("401 Unauthorized"))
+		})
+
+		It("should exit with 4 when toggle-errands cannot log in", func() {
+			session := run("toggle-errands", "--action=disable")
+			Eventually(session, 10).Should(gexec.Exit(4))
+		})
+	})
 })